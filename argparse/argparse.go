@@ -9,6 +9,9 @@ type Opts struct {
 
 	// REPL
 	PrintAST bool
+
+	// Run
+	Allow []string
 }
 
 func Parse(version string) *Opts {
@@ -49,6 +49,32 @@ func (es *ReturnStatement) String() string {
 	return ""
 }
 
+// LetStatement binds a new variable in the current scope, shadowing any outer binding.
+type LetStatement struct {
+	Token  *token.Token
+	Target *Identifier
+	Value  Expression
+}
+
+func (ls *LetStatement) Name() string   { return "LetStatement" }
+func (ls *LetStatement) statementNode() {}
+func (ls *LetStatement) String() string {
+	return fmt.Sprintf("let %s = %s", ls.Target, ls.Value)
+}
+
+// ConstStatement binds a constant in the current scope.
+type ConstStatement struct {
+	Token  *token.Token
+	Target *Identifier
+	Value  Expression
+}
+
+func (cs *ConstStatement) Name() string   { return "ConstStatement" }
+func (cs *ConstStatement) statementNode() {}
+func (cs *ConstStatement) String() string {
+	return fmt.Sprintf("const %s = %s", cs.Target, cs.Value)
+}
+
 type Integer struct {
 	Token *token.Token
 	Value int64
@@ -90,6 +116,14 @@ func (b *Boolean) Name() string    { return "Boolean" }
 func (b *Boolean) expressionNode() {}
 func (b *Boolean) String() string  { return fmt.Sprintf("%t", b.Value) }
 
+type Null struct {
+	Token *token.Token
+}
+
+func (n *Null) Name() string    { return "Null" }
+func (n *Null) expressionNode() {}
+func (n *Null) String() string  { return "null" }
+
 type InfixExpression struct {
 	Left     Expression
 	Operator token.TokenType
@@ -123,6 +157,34 @@ func (ae *AssignmentExpression) String() string {
 	return fmt.Sprintf("%s = %s", ae.Target, ae.Value.String())
 }
 
+// CompoundAssignment is `target op= value`, Operator is the arithmetic operator applied.
+type CompoundAssignment struct {
+	Token    *token.Token
+	Target   Expression
+	Operator token.TokenType
+	Value    Expression
+}
+
+func (ca *CompoundAssignment) Name() string    { return "CompoundAssignment" }
+func (ca *CompoundAssignment) expressionNode() {}
+func (ca *CompoundAssignment) String() string {
+	return fmt.Sprintf("%s %s= %s", ca.Target, ca.Operator, ca.Value.String())
+}
+
+// PipeExpression is `left |> right`, calling right with left as the first argument.
+// When right is a call, left goes before its arguments.
+type PipeExpression struct {
+	Token *token.Token
+	Left  Expression
+	Right Expression
+}
+
+func (pe *PipeExpression) Name() string    { return "PipeExpression" }
+func (pe *PipeExpression) expressionNode() {}
+func (pe *PipeExpression) String() string {
+	return fmt.Sprintf("(%s |> %s)", pe.Left.String(), pe.Right.String())
+}
+
 type IfExpression struct {
 	Token       *token.Token
 	Condition   Expression
@@ -153,6 +215,52 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// MatchExpression evaluates the body of the first case whose pattern matches the value.
+type MatchExpression struct {
+	Token *token.Token
+	Value Expression
+	Cases []*MatchCase
+}
+
+func (me *MatchExpression) Name() string    { return "MatchExpression" }
+func (me *MatchExpression) expressionNode() {}
+func (me *MatchExpression) String() string {
+	var out strings.Builder
+	out.WriteString("match ")
+	out.WriteString(me.Value.String())
+	out.WriteString("\n")
+	for _, c := range me.Cases {
+		out.WriteString(c.String())
+	}
+	out.WriteString("end")
+	return out.String()
+}
+
+// MatchCase is a single `case` arm. Patterns are literals, `_`, names binding
+// the value, arrays, maps and class patterns like `Point(x, y)`.
+type MatchCase struct {
+	Token   *token.Token
+	Pattern Expression
+	// Guard is nil when the case has no `if`.
+	Guard Expression
+	Body  *BlockStatement
+}
+
+func (mc *MatchCase) Name() string    { return "MatchCase" }
+func (mc *MatchCase) expressionNode() {}
+func (mc *MatchCase) String() string {
+	var out strings.Builder
+	out.WriteString("case ")
+	out.WriteString(mc.Pattern.String())
+	if mc.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(mc.Guard.String())
+	}
+	out.WriteString(" then\n")
+	out.WriteString(mc.Body.String())
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      *token.Token
 	Statements []Statement
@@ -191,6 +299,35 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// KeywordArgument is a `name: value` argument of a call.
+type KeywordArgument struct {
+	Token *token.Token
+	Key   string
+	Value Expression
+}
+
+func (ka *KeywordArgument) Name() string    { return "KeywordArgument" }
+func (ka *KeywordArgument) expressionNode() {}
+func (ka *KeywordArgument) String() string {
+	return fmt.Sprintf("%s: %s", ka.Key, ka.Value)
+}
+
+// Parameters renders a parameter list like `a, b = 1, ...rest`.
+func Parameters(args []*Identifier, defaults map[string]Expression, rest *Identifier) string {
+	params := []string{}
+	for _, arg := range args {
+		if value, ok := defaults[arg.Value]; ok {
+			params = append(params, fmt.Sprintf("%s = %s", arg, value))
+		} else {
+			params = append(params, arg.String())
+		}
+	}
+	if rest != nil {
+		params = append(params, "..."+rest.String())
+	}
+	return strings.Join(params, ", ")
+}
+
 type WhileStatement struct {
 	Token       *token.Token
 	Condition   Expression
@@ -212,7 +349,14 @@ func (ws *WhileStatement) String() string {
 type Lambda struct {
 	Token     *token.Token
 	Arguments []*Identifier
-	Body      *BlockStatement
+	// Defaults holds the default values by parameter name.
+	Defaults map[string]Expression
+	// Rest collects the extra positional arguments, nil if there is none.
+	Rest *Identifier
+	// Patterns holds the list and map patterns destructuring arguments, by the
+	// name of the parameter standing for them.
+	Patterns map[string]Expression
+	Body     *BlockStatement
 }
 
 func (l *Lambda) Name() string    { return "Lambda" }
@@ -220,12 +364,7 @@ func (l *Lambda) expressionNode() {}
 func (l *Lambda) String() string {
 	var out strings.Builder
 	out.WriteString("fn(")
-	for i, arg := range l.Arguments {
-		out.WriteString(arg.String())
-		if i != len(l.Arguments)-1 {
-			out.WriteString(", ")
-		}
-	}
+	out.WriteString(Parameters(l.Arguments, l.Defaults, l.Rest))
 	out.WriteString(")\n")
 	out.WriteString(l.Body.String())
 	out.WriteString("end")
@@ -236,7 +375,14 @@ type FunctionStatement struct {
 	Token     *token.Token
 	Target    *Identifier
 	Arguments []*Identifier
-	Body      *BlockStatement
+	// Defaults holds the default values by parameter name.
+	Defaults map[string]Expression
+	// Rest collects the extra positional arguments, nil if there is none.
+	Rest *Identifier
+	// Patterns holds the list and map patterns destructuring arguments, by the
+	// name of the parameter standing for them.
+	Patterns map[string]Expression
+	Body     *BlockStatement
 }
 
 func (fs *FunctionStatement) Name() string   { return "FunctionStatement" }
@@ -246,12 +392,7 @@ func (fs *FunctionStatement) String() string {
 	out.WriteString("fn ")
 	out.WriteString(fs.Target.String())
 	out.WriteString("(")
-	for i, arg := range fs.Arguments {
-		out.WriteString(arg.String())
-		if i != len(fs.Arguments)-1 {
-			out.WriteString(", ")
-		}
-	}
+	out.WriteString(Parameters(fs.Arguments, fs.Defaults, fs.Rest))
 	out.WriteString(")\n")
 	out.WriteString(fs.Body.String())
 	out.WriteString("end")
@@ -288,6 +429,27 @@ func (ie *IndexExpression) String() string {
 	return fmt.Sprintf("(%s[%s])", ie.Left.String(), ie.Index.String())
 }
 
+// SliceExpression is `left[start:end]`, Start and End are nil when that side is open.
+type SliceExpression struct {
+	Token *token.Token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (se *SliceExpression) Name() string    { return "SliceExpression" }
+func (se *SliceExpression) expressionNode() {}
+func (se *SliceExpression) String() string {
+	start, end := "", ""
+	if se.Start != nil {
+		start = se.Start.String()
+	}
+	if se.End != nil {
+		end = se.End.String()
+	}
+	return fmt.Sprintf("(%s[%s:%s])", se.Left.String(), start, end)
+}
+
 type Array struct {
 	Token    *token.Token
 	Elements []Expression
@@ -308,6 +470,29 @@ func (a *Array) String() string {
 	return out.String()
 }
 
+type Map struct {
+	Token  *token.Token
+	Keys   []Expression
+	Values []Expression
+}
+
+func (m *Map) Name() string    { return "Map" }
+func (m *Map) expressionNode() {}
+func (m *Map) String() string {
+	var out strings.Builder
+	out.WriteString("{")
+	for i, key := range m.Keys {
+		out.WriteString(key.String())
+		out.WriteString(" = ")
+		out.WriteString(m.Values[i].String())
+		if i != len(m.Keys)-1 {
+			out.WriteString(", ")
+		}
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
 type PrefixExpression struct {
 	Token    *token.Token
 	Operator token.TokenType
@@ -321,9 +506,16 @@ func (pe *PrefixExpression) String() string {
 }
 
 type ClassStatement struct {
-	Token   *token.Token
-	Target  *Identifier
+	Token  *token.Token
+	Target *Identifier
+	// Includes are the traits whose methods are merged into the class.
+	Includes  []*Identifier
+	Constants []*ConstStatement
+	// Fields are assigned to every new instance before init is called.
+	Fields  []*AssignmentExpression
 	Methods []*FunctionStatement
+	// Statics are called on the class itself, they have no `this`.
+	Statics []*FunctionStatement
 }
 
 func (cs *ClassStatement) Name() string   { return "ClassStatement" }
@@ -333,6 +525,24 @@ func (cs *ClassStatement) String() string {
 	out.WriteString("class ")
 	out.WriteString(cs.Target.Value)
 	out.WriteString("\n")
+	for _, include := range cs.Includes {
+		out.WriteString("include ")
+		out.WriteString(include.Value)
+		out.WriteString("\n")
+	}
+	for _, constant := range cs.Constants {
+		out.WriteString(constant.String())
+		out.WriteString("\n")
+	}
+	for _, field := range cs.Fields {
+		out.WriteString(field.String())
+		out.WriteString("\n")
+	}
+	for _, method := range cs.Statics {
+		out.WriteString("static ")
+		out.WriteString(method.String())
+		out.WriteString("\n")
+	}
 	for _, method := range cs.Methods {
 		out.WriteString(method.String())
 		out.WriteString("\n")
@@ -341,14 +551,60 @@ func (cs *ClassStatement) String() string {
 	return out.String()
 }
 
+// TraitStatement declares methods shared by the classes including it.
+type TraitStatement struct {
+	Token   *token.Token
+	Target  *Identifier
+	Methods []*FunctionStatement
+}
+
+func (ts *TraitStatement) Name() string   { return "TraitStatement" }
+func (ts *TraitStatement) statementNode() {}
+func (ts *TraitStatement) String() string {
+	var out strings.Builder
+	out.WriteString("trait ")
+	out.WriteString(ts.Target.Value)
+	out.WriteString("\n")
+	for _, method := range ts.Methods {
+		out.WriteString(method.String())
+		out.WriteString("\n")
+	}
+	out.WriteString("end\n")
+	return out.String()
+}
+
 type FieldExpression struct {
 	Token  *token.Token
 	Target Expression
 	Field  *token.Token
+	// Optional expressions (`a?.b`) are null when the target is null.
+	Optional bool
 }
 
 func (fe *FieldExpression) Name() string    { return "FieldExpression" }
 func (fe *FieldExpression) expressionNode() {}
 func (fe *FieldExpression) String() string {
-	return fmt.Sprintf("%s.%s", fe.Target.String(), fe.Field.String())
+	if fe.Optional {
+		return fmt.Sprintf("%s?.%s", fe.Target.String(), fe.Field.Value)
+	}
+	return fmt.Sprintf("%s.%s", fe.Target.String(), fe.Field.Value)
+}
+
+type ImportStatement struct {
+	Token *token.Token
+	Path  *String
+	Alias *Identifier
+}
+
+func (is *ImportStatement) Name() string   { return "ImportStatement" }
+func (is *ImportStatement) statementNode() {}
+func (is *ImportStatement) String() string {
+	var out strings.Builder
+	out.WriteString("import ")
+	out.WriteString(is.Path.String())
+	if is.Alias != nil {
+		out.WriteString(" as ")
+		out.WriteString(is.Alias.String())
+	}
+	return out.String()
 }
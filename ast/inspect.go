@@ -0,0 +1,141 @@
+package ast
+
+import "reflect"
+
+// Inspect walks the tree in depth-first order, calling fn for every node.
+// If fn returns false the children of that node are skipped.
+func Inspect(node Node, fn func(Node) bool) {
+	if isNil(node) || !fn(node) {
+		return
+	}
+
+	for _, child := range children(node) {
+		Inspect(child, fn)
+	}
+}
+
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(node)
+
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+func children(node Node) []Node {
+	nodes := []Node{}
+
+	add := func(children ...Node) {
+		for _, child := range children {
+			if !isNil(child) {
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for _, s := range node.Statements {
+			add(s)
+		}
+	case *BlockStatement:
+		for _, s := range node.Statements {
+			add(s)
+		}
+	case *ReturnStatement:
+		add(node.Value)
+	case *LetStatement:
+		add(node.Target, node.Value)
+	case *ConstStatement:
+		add(node.Target, node.Value)
+	case *ExpressionStatement:
+		add(node.Expression)
+	case *InfixExpression:
+		add(node.Left, node.Right)
+	case *PrefixExpression:
+		add(node.Right)
+	case *AssignmentExpression:
+		add(node.Target, node.Value)
+	case *CompoundAssignment:
+		add(node.Target, node.Value)
+	case *PipeExpression:
+		add(node.Left, node.Right)
+	case *IfExpression:
+		add(node.Condition, node.Consequence)
+		for condition, block := range node.Elif {
+			add(condition, block)
+		}
+		add(node.Alternative)
+	case *CallExpression:
+		add(node.Function)
+		for _, arg := range node.Arguments {
+			add(arg)
+		}
+	case *WhileStatement:
+		add(node.Condition, node.Consequence)
+	case *Lambda:
+		for _, arg := range node.Arguments {
+			add(arg, node.Patterns[arg.Value], node.Defaults[arg.Value])
+		}
+		add(node.Rest, node.Body)
+	case *FunctionStatement:
+		add(node.Target)
+		for _, arg := range node.Arguments {
+			add(arg, node.Patterns[arg.Value], node.Defaults[arg.Value])
+		}
+		add(node.Rest, node.Body)
+	case *KeywordArgument:
+		add(node.Value)
+	case *TestStatement:
+		add(node.Target, node.Statements)
+	case *IndexExpression:
+		add(node.Left, node.Index)
+	case *SliceExpression:
+		add(node.Left, node.Start, node.End)
+	case *Array:
+		for _, element := range node.Elements {
+			add(element)
+		}
+	case *Map:
+		for i := range node.Keys {
+			add(node.Keys[i], node.Values[i])
+		}
+	case *ClassStatement:
+		add(node.Target)
+		for _, include := range node.Includes {
+			add(include)
+		}
+		for _, constant := range node.Constants {
+			add(constant)
+		}
+		for _, field := range node.Fields {
+			add(field)
+		}
+		for _, method := range node.Statics {
+			add(method)
+		}
+		for _, method := range node.Methods {
+			add(method)
+		}
+	case *TraitStatement:
+		add(node.Target)
+		for _, method := range node.Methods {
+			add(method)
+		}
+	case *MatchExpression:
+		add(node.Value)
+		for _, c := range node.Cases {
+			add(c)
+		}
+	case *MatchCase:
+		add(node.Pattern, node.Guard, node.Body)
+	case *FieldExpression:
+		add(node.Target)
+	case *ImportStatement:
+		add(node.Path, node.Alias)
+	}
+
+	return nodes
+}
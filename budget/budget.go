@@ -0,0 +1,126 @@
+// Package budget limits how much work a fener program can do.
+package budget
+
+import (
+	"context"
+	"fmt"
+)
+
+// The limits a LimitError can name.
+const (
+	STEPS   = "steps"
+	DEPTH   = "depth"
+	CONTEXT = "context"
+)
+
+// contextCheckInterval is the number of steps between checks of the context.
+const contextCheckInterval = 256
+
+// Limits are the maximum number of steps and the maximum call depth, zero means unlimited.
+// Only the evaluator checks the call depth, the VM has no calls.
+type Limits struct {
+	MaxSteps int
+	MaxDepth int
+}
+
+// LimitError is reported when a program exceeds its budget.
+type LimitError struct {
+	Limit string
+	Max   int
+	Err   error
+}
+
+func (l *LimitError) Error() string {
+	switch l.Limit {
+	case STEPS:
+		return fmt.Sprintf("step limit exceeded: more than %d steps", l.Max)
+	case DEPTH:
+		return fmt.Sprintf("call depth limit exceeded: more than %d nested calls", l.Max)
+	default:
+		return fmt.Sprintf("execution cancelled: %v", l.Err)
+	}
+}
+
+func (l *LimitError) Unwrap() error {
+	return l.Err
+}
+
+// Budget counts the steps and calls of a single run.
+// Once a limit is exceeded every later check fails with the same error.
+type Budget struct {
+	ctx    context.Context
+	limits Limits
+
+	steps int
+	depth int
+	err   *LimitError
+}
+
+func New(ctx context.Context, limits Limits) *Budget {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return &Budget{
+		ctx:    ctx,
+		limits: limits,
+	}
+}
+
+// Step records a single unit of work, a node evaluation or a VM instruction.
+func (b *Budget) Step() error {
+	if b.err != nil {
+		return b.err
+	}
+
+	b.steps++
+
+	if b.limits.MaxSteps > 0 && b.steps > b.limits.MaxSteps {
+		return b.fail(&LimitError{Limit: STEPS, Max: b.limits.MaxSteps})
+	}
+
+	if b.steps%contextCheckInterval == 1 {
+		if err := b.ctx.Err(); err != nil {
+			return b.fail(&LimitError{Limit: CONTEXT, Err: err})
+		}
+	}
+
+	return nil
+}
+
+// Enter records a function call, a successful Enter must be paired with Leave.
+func (b *Budget) Enter() error {
+	if b.err != nil {
+		return b.err
+	}
+
+	if b.limits.MaxDepth > 0 && b.depth >= b.limits.MaxDepth {
+		return b.fail(&LimitError{Limit: DEPTH, Max: b.limits.MaxDepth})
+	}
+
+	b.depth++
+
+	return nil
+}
+
+func (b *Budget) Leave() {
+	b.depth--
+}
+
+// Context returns the context the budget checks, builtins waiting on I/O should stop when it's done.
+func (b *Budget) Context() context.Context {
+	return b.ctx
+}
+
+// Err returns the error of the exceeded limit, if any.
+func (b *Budget) Err() error {
+	if b.err == nil {
+		return nil
+	}
+	return b.err
+}
+
+func (b *Budget) fail(err *LimitError) error {
+	b.err = err
+	return err
+}
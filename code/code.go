@@ -12,6 +12,7 @@ const (
 	SUB
 	DIV
 	MUL
+	MOD
 
 	TRUE
 	FALSE
@@ -22,6 +23,7 @@ const (
 	EQ
 	NOT
 	NEQ
+	NEG
 
 	JCMP
 	JMP
@@ -29,6 +31,19 @@ const (
 
 	SET
 	GET
+
+	NULL
+	// JNOTNULL jumps if the top of the stack isn't null, otherwise it pops it.
+	JNOTNULL
+	// NOMATCH fails with the value on top of the stack, no case of a match accepted it.
+	NOMATCH
+
+	// RANGE builds a range from the two integers on top of the stack, its operand is 1 to leave the end out.
+	RANGE
+	// INDEX looks up the index on top of the stack in the value below it.
+	INDEX
+	// SLICE slices the value below the start and end bounds, null bounds are open.
+	SLICE
 )
 
 type Instruction struct {
@@ -48,6 +63,7 @@ var definitions = map[OpCode]Definition{
 	SUB: {SUB, 0},
 	DIV: {DIV, 0},
 	MUL: {MUL, 0},
+	MOD: {MOD, 0},
 
 	TRUE:  {TRUE, 0},
 	FALSE: {FALSE, 0},
@@ -58,12 +74,21 @@ var definitions = map[OpCode]Definition{
 	EQ:    {EQ, 0},
 	NOT:   {NOT, 0},
 	NEQ:   {NEQ, 0},
+	NEG:   {NEG, 0},
 
 	SET: {SET, 1},
 	GET: {GET, 1},
 
 	JCMP: {JCMP, 1},
 	JMP:  {JMP, 1},
+
+	NULL:     {NULL, 0},
+	JNOTNULL: {JNOTNULL, 1},
+	NOMATCH:  {NOMATCH, 0},
+
+	RANGE: {RANGE, 1},
+	INDEX: {INDEX, 0},
+	SLICE: {SLICE, 0},
 }
 
 func Make(op OpCode, operands ...int) *Instruction {
@@ -13,25 +13,33 @@ func _() {
 	_ = x[SUB-2]
 	_ = x[DIV-3]
 	_ = x[MUL-4]
-	_ = x[TRUE-5]
-	_ = x[FALSE-6]
-	_ = x[AND-7]
-	_ = x[OR-8]
-	_ = x[GT-9]
-	_ = x[LT-10]
-	_ = x[EQ-11]
-	_ = x[NOT-12]
-	_ = x[NEQ-13]
-	_ = x[JCMP-14]
-	_ = x[JMP-15]
-	_ = x[JT-16]
-	_ = x[SET-17]
-	_ = x[GET-18]
+	_ = x[MOD-5]
+	_ = x[TRUE-6]
+	_ = x[FALSE-7]
+	_ = x[AND-8]
+	_ = x[OR-9]
+	_ = x[GT-10]
+	_ = x[LT-11]
+	_ = x[EQ-12]
+	_ = x[NOT-13]
+	_ = x[NEQ-14]
+	_ = x[NEG-15]
+	_ = x[JCMP-16]
+	_ = x[JMP-17]
+	_ = x[JT-18]
+	_ = x[SET-19]
+	_ = x[GET-20]
+	_ = x[NULL-21]
+	_ = x[JNOTNULL-22]
+	_ = x[NOMATCH-23]
+	_ = x[RANGE-24]
+	_ = x[INDEX-25]
+	_ = x[SLICE-26]
 }
 
-const _OpCode_name = "PUSHADDSUBDIVMULTRUEFALSEANDORGTLTEQNOTNEQJCMPJMPJTSETGET"
+const _OpCode_name = "PUSHADDSUBDIVMULMODTRUEFALSEANDORGTLTEQNOTNEQNEGJCMPJMPJTSETGETNULLJNOTNULLNOMATCHRANGEINDEXSLICE"
 
-var _OpCode_index = [...]uint8{0, 4, 7, 10, 13, 16, 20, 25, 28, 30, 32, 34, 36, 39, 42, 46, 49, 51, 54, 57}
+var _OpCode_index = [...]uint8{0, 4, 7, 10, 13, 16, 19, 23, 28, 31, 33, 35, 37, 39, 42, 45, 48, 52, 55, 57, 60, 63, 67, 75, 82, 87, 92, 97}
 
 func (i OpCode) String() string {
 	if i >= OpCode(len(_OpCode_index)-1) {
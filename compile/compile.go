@@ -63,20 +63,41 @@ func (c *Compiler) Compile(node ast.Node) error {
 		return c.compileInfixExpression(node)
 	case *ast.Boolean:
 		return c.compileBoolean(node)
+	case *ast.Null:
+		return c.emit(code.NULL)
 	case *ast.PrefixExpression:
 		return c.compilePrefixExpression(node)
 	case *ast.AssignmentExpression:
 		return c.compileAssignmentExpression(node)
+	case *ast.CompoundAssignment:
+		return c.compileCompoundAssignment(node)
+	case *ast.LetStatement:
+		return c.compileLetStatement(node)
+	case *ast.ConstStatement:
+		return c.compileConstStatement(node)
 	case *ast.Identifier:
 		return c.compileIdentifier(node)
 	case *ast.IfExpression:
 		return c.compileIfExpression(node)
+	case *ast.MatchExpression:
+		return c.compileMatchExpression(node)
+	case *ast.IndexExpression:
+		return c.compileIndexExpression(node)
+	case *ast.SliceExpression:
+		return c.compileSliceExpression(node)
 	case *ast.BlockStatement:
 		return c.compileBlockStatement(node)
 	case *ast.WhileStatement:
 		return c.compileWhileStatement(node)
 	// case *ast.FunctionStatement:
 	// 	return c.compileFunctionStatement(node)
+	case *ast.PipeExpression:
+		// Pipelines call functions, which the compiler doesn't support yet.
+		return fmt.Errorf("pipelines are not supported by the compiler yet, run the program with the evaluator")
+	case *ast.ClassStatement, *ast.TraitStatement:
+		// Methods need functions, which the compiler doesn't support yet.
+		// Merging traits into classes, like evalClassStatement does, waits on compiled classes.
+		return fmt.Errorf("classes and traits are not supported by the compiler yet, run the program with the evaluator")
 	default:
 		return fmt.Errorf("unknown node type %T", node)
 	}
@@ -192,12 +213,37 @@ func (c *Compiler) compileIdentifier(node *ast.Identifier) error {
 	return c.emit(code.GET, sym.Index)
 }
 func (c *Compiler) addAssignment(target *ast.Identifier) error {
-	sym, ok := c.symbols.Resolve(target.Value)
+	sym, err := c.symbols.Assign(target.Value)
+	if err != nil {
+		return err
+	}
 
-	if !ok {
-		sym = c.symbols.Define(target.Value)
+	return c.emit(code.SET, sym.Index)
+}
+func (c *Compiler) compileLetStatement(node *ast.LetStatement) error {
+	err := c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
 
+	sym, err := c.symbols.Define(node.Target.Value)
+	if err != nil {
+		return err
 	}
+
+	return c.emit(code.SET, sym.Index)
+}
+func (c *Compiler) compileConstStatement(node *ast.ConstStatement) error {
+	err := c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
+
+	sym, err := c.symbols.DefineConst(node.Target.Value)
+	if err != nil {
+		return err
+	}
+
 	return c.emit(code.SET, sym.Index)
 }
 func (c *Compiler) compileAssignmentExpression(node *ast.AssignmentExpression) error {
@@ -214,6 +260,46 @@ func (c *Compiler) compileAssignmentExpression(node *ast.AssignmentExpression) e
 		return fmt.Errorf("unknown target type %T", target)
 	}
 }
+
+// compoundOpcodes are the opcodes applied by the compound assignments.
+var compoundOpcodes = map[token.TokenType]code.OpCode{
+	token.PLUS:     code.ADD,
+	token.MINUS:    code.SUB,
+	token.MULTIPLY: code.MUL,
+	token.DIVIDE:   code.DIV,
+	token.MOD:      code.MOD,
+}
+
+// compileCompoundAssignment reads the variable, applies the operator and stores the result.
+// Fields and indexes need instances and lists, which the compiler doesn't support yet.
+func (c *Compiler) compileCompoundAssignment(node *ast.CompoundAssignment) error {
+	target, ok := node.Target.(*ast.Identifier)
+	if !ok {
+		return fmt.Errorf("compound assignment to %s is not supported by the compiler yet", node.Target.String())
+	}
+
+	op, ok := compoundOpcodes[node.Operator]
+	if !ok {
+		return fmt.Errorf("unknown compound operator '%s='", node.Operator)
+	}
+
+	err := c.compileIdentifier(target)
+	if err != nil {
+		return err
+	}
+
+	err = c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
+
+	err = c.emit(op)
+	if err != nil {
+		return err
+	}
+
+	return c.addAssignment(target)
+}
 func (c *Compiler) compilePrefixExpression(node *ast.PrefixExpression) error {
 	err := c.Compile(node.Right)
 	if err != nil {
@@ -223,6 +309,8 @@ func (c *Compiler) compilePrefixExpression(node *ast.PrefixExpression) error {
 	switch node.Operator {
 	case token.BANG:
 		return c.emit(code.NOT)
+	case token.MINUS:
+		return c.emit(code.NEG)
 	default:
 		return fmt.Errorf("unknown prefix operator '%s'", node.Operator)
 	}
@@ -236,6 +324,10 @@ func (c *Compiler) compileBoolean(node *ast.Boolean) error {
 	return c.emit(code.FALSE)
 }
 func (c *Compiler) compileInfixExpression(node *ast.InfixExpression) error {
+	if node.Operator == token.COALESCE {
+		return c.compileCoalesce(node)
+	}
+
 	err := c.Compile(node.Left)
 	if err != nil {
 		return err
@@ -255,6 +347,8 @@ func (c *Compiler) compileInfixExpression(node *ast.InfixExpression) error {
 		return c.emit(code.MUL)
 	case token.DIVIDE:
 		return c.emit(code.DIV)
+	case token.MOD:
+		return c.emit(code.MOD)
 	case token.LT:
 		return c.emit(code.LT)
 	case token.GT:
@@ -267,10 +361,71 @@ func (c *Compiler) compileInfixExpression(node *ast.InfixExpression) error {
 		return c.emit(code.OR)
 	case token.NOT_EQ:
 		return c.emit(code.NEQ)
+	case token.RANGE:
+		return c.emit(code.RANGE, 0)
+	case token.ELLIPSIS:
+		return c.emit(code.RANGE, 1)
 	default:
 		return fmt.Errorf("unknown infix operator '%s'", node.Operator)
 	}
 }
+
+// compileCoalesce keeps the left side when it isn't null, skipping the right one.
+func (c *Compiler) compileCoalesce(node *ast.InfixExpression) error {
+	err := c.Compile(node.Left)
+	if err != nil {
+		return err
+	}
+
+	jumpId := len(c.instructions)
+	err = c.emit(code.JNOTNULL, 99999) // placeholder
+	if err != nil {
+		return err
+	}
+
+	err = c.Compile(node.Right)
+	if err != nil {
+		return err
+	}
+
+	c.jumpTable[jumpId] = len(c.instructions)
+
+	return nil
+}
+func (c *Compiler) compileIndexExpression(node *ast.IndexExpression) error {
+	err := c.Compile(node.Left)
+	if err != nil {
+		return err
+	}
+
+	err = c.Compile(node.Index)
+	if err != nil {
+		return err
+	}
+
+	return c.emit(code.INDEX)
+}
+
+// compileSliceExpression pushes null for an open bound.
+func (c *Compiler) compileSliceExpression(node *ast.SliceExpression) error {
+	err := c.Compile(node.Left)
+	if err != nil {
+		return err
+	}
+
+	for _, bound := range []ast.Expression{node.Start, node.End} {
+		if bound == nil {
+			err = c.emit(code.NULL)
+		} else {
+			err = c.Compile(bound)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.emit(code.SLICE)
+}
 func (c *Compiler) compileInteger(node *ast.Integer) error {
 	integer := &object.Integer{Value: node.Value}
 
@@ -112,6 +112,106 @@ func TestVariableReassignment(t *testing.T) {
 	testBytecode(t, input, bytecode, constants)
 }
 
+func TestLet(t *testing.T) {
+	input := `let a = 5 b = 1 let a = 10`
+
+	constants := []interface{}{5, 1, 10}
+	bytecode := []*code.Instruction{
+		code.Make(code.PUSH, 0), // Push 5
+		code.Make(code.SET, 0),  // Set variable 'a'
+		code.Make(code.PUSH, 1), // Push 1
+		code.Make(code.SET, 1),  // Set variable 'b'
+		code.Make(code.PUSH, 2), // Push 10
+		code.Make(code.SET, 0),  // Set variable 'a'
+	}
+	testBytecode(t, input, bytecode, constants)
+}
+
+func TestConst(t *testing.T) {
+	input := `const a = 5 a`
+
+	constants := []interface{}{5}
+	bytecode := []*code.Instruction{
+		code.Make(code.PUSH, 0), // Push 5
+		code.Make(code.SET, 0),  // Set constant 'a'
+		code.Make(code.GET, 0),  // Get constant 'a'
+	}
+	testBytecode(t, input, bytecode, constants)
+
+	for _, input := range []string{"const a = 5 a = 6", "const a = 5 let a = 6", "const a = 5 const a = 6"} {
+		program := parser.New(lexer.New(input)).Parse()
+
+		err := New().Compile(program)
+
+		if err == nil || err.Error() != "can't assign to constant a" {
+			t.Errorf("Expected constant error for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	input := `null ?? 5`
+
+	constants := []interface{}{5}
+	bytecode := []*code.Instruction{
+		code.Make(code.NULL),        // Push null 0000
+		code.Make(code.JNOTNULL, 7), // Keep the left side unless it's null 0001
+		code.Make(code.PUSH, 0),     // Push 5 0004
+	}
+	testBytecode(t, input, bytecode, constants)
+}
+
+func TestMatch(t *testing.T) {
+	input := `match 1 case 2 then 3 case _ then 4 end`
+
+	constants := []interface{}{1, 2, 3, 4}
+	bytecode := []*code.Instruction{
+		code.Make(code.PUSH, 0),  // Push 1 0000
+		code.Make(code.SET, 0),   // Set the hidden match value 0003
+		code.Make(code.GET, 0),   // Get the match value 0006
+		code.Make(code.PUSH, 1),  // Push 2 0009
+		code.Make(code.EQ),       // Compare 0012
+		code.Make(code.JCMP, 22), // Next case unless equal 0013
+		code.Make(code.PUSH, 2),  // Push 3 0016
+		code.Make(code.JMP, 32),  // Jump to the end 0019
+		code.Make(code.PUSH, 3),  // Push 4 0022
+		code.Make(code.JMP, 32),  // Jump to the end 0025
+		code.Make(code.GET, 0),   // Get the match value 0028
+		code.Make(code.NOMATCH),  // No case matched 0031
+	}
+	testBytecode(t, input, bytecode, constants)
+
+	program := parser.New(lexer.New("match 1 case [a] then a end")).Parse()
+
+	err := New().Compile(program)
+
+	if err == nil || err.Error() != "pattern [a] is not supported by the compiler yet" {
+		t.Errorf("Expected unsupported pattern error, got %v", err)
+	}
+}
+
+func TestClassesUnsupported(t *testing.T) {
+	for _, input := range []string{"class A end", "trait T end", "trait T end class A include T end"} {
+		program := parser.New(lexer.New(input)).Parse()
+
+		err := New().Compile(program)
+
+		if err == nil || err.Error() != "classes and traits are not supported by the compiler yet, run the program with the evaluator" {
+			t.Errorf("Expected unsupported error for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestPipeUnsupported(t *testing.T) {
+	program := parser.New(lexer.New("a = 1 a |> f()")).Parse()
+
+	err := New().Compile(program)
+
+	if err == nil || err.Error() != "pipelines are not supported by the compiler yet, run the program with the evaluator" {
+		t.Errorf("Expected unsupported error, got %v", err)
+	}
+}
+
 func TestVariableAssignment(t *testing.T) {
 	input := `a = 5`
 
@@ -125,6 +225,48 @@ func TestVariableAssignment(t *testing.T) {
 	testBytecode(t, input, bytecode, constants)
 }
 
+func TestCompoundAssignment(t *testing.T) {
+	input := `a = 5 a %= 2`
+
+	constants := []interface{}{5, 2}
+
+	bytecode := []*code.Instruction{
+		code.Make(code.PUSH, 0), // Push 5
+		code.Make(code.SET, 0),  // Set variable 'a'
+		code.Make(code.GET, 0),  // Get variable 'a'
+		code.Make(code.PUSH, 1), // Push 2
+		code.Make(code.MOD),     // Apply the operator
+		code.Make(code.SET, 0),  // Set variable 'a'
+	}
+
+	testBytecode(t, input, bytecode, constants)
+
+	program := parser.New(lexer.New("a = 1 a.b += 1")).Parse()
+
+	err := New().Compile(program)
+
+	if err == nil || err.Error() != "compound assignment to a.b is not supported by the compiler yet" {
+		t.Errorf("Expected unsupported target error, got %v", err)
+	}
+}
+
+func TestRangeSlice(t *testing.T) {
+	input := `(1...4)[2:]`
+
+	constants := []interface{}{1, 4, 2}
+
+	bytecode := []*code.Instruction{
+		code.Make(code.PUSH, 0),  // Push 1
+		code.Make(code.PUSH, 1),  // Push 4
+		code.Make(code.RANGE, 1), // Build the range leaving 4 out
+		code.Make(code.PUSH, 2),  // Push 2
+		code.Make(code.NULL),     // The end is open
+		code.Make(code.SLICE),    // Slice the range
+	}
+
+	testBytecode(t, input, bytecode, constants)
+}
+
 func TestVariableRetrieval(t *testing.T) {
 	input := `a = 5  a`
 
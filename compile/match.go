@@ -0,0 +1,144 @@
+package compile
+
+import (
+	"fmt"
+
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/code"
+)
+
+// compileMatchExpression stores the value in a hidden variable and tests the cases in order.
+// Each case is compiled in its own block scope, so the names bound by its pattern don't
+// overwrite the variables outside the match. Only literal, `_` and name patterns are supported.
+func (c *Compiler) compileMatchExpression(node *ast.MatchExpression) error {
+	err := c.Compile(node.Value)
+	if err != nil {
+		return err
+	}
+
+	outer := c.symbols
+	c.symbols = NewBlockSymbolTable(outer)
+	defer func() { c.symbols = outer }()
+
+	// The name can't clash with an identifier.
+	value, err := c.symbols.Define("match value")
+	if err != nil {
+		return err
+	}
+
+	err = c.emit(code.SET, value.Index)
+	if err != nil {
+		return err
+	}
+
+	ends := []int{}
+
+	for _, mc := range node.Cases {
+		end, err := c.compileMatchCase(mc, value.Index)
+		if err != nil {
+			return err
+		}
+		ends = append(ends, end)
+	}
+
+	err = c.emit(code.GET, value.Index)
+	if err != nil {
+		return err
+	}
+
+	err = c.emit(code.NOMATCH)
+	if err != nil {
+		return err
+	}
+
+	for _, end := range ends {
+		c.jumpTable[end] = len(c.instructions)
+	}
+
+	return nil
+}
+
+// compileMatchCase returns the jump to the end of the match taken after the body.
+func (c *Compiler) compileMatchCase(mc *ast.MatchCase, value int) (int, error) {
+	outer := c.symbols
+	c.symbols = NewBlockSymbolTable(outer)
+	defer func() { c.symbols = outer }()
+
+	nexts, err := c.compilePattern(mc.Pattern, value)
+	if err != nil {
+		return 0, err
+	}
+
+	if mc.Guard != nil {
+		err = c.Compile(mc.Guard)
+		if err != nil {
+			return 0, err
+		}
+
+		nexts = append(nexts, len(c.instructions))
+		err = c.emit(code.JCMP, 99999) // placeholder
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = c.Compile(mc.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	end := len(c.instructions)
+	err = c.emit(code.JMP, 99999) // placeholder
+	if err != nil {
+		return 0, err
+	}
+
+	for _, next := range nexts {
+		c.jumpTable[next] = len(c.instructions)
+	}
+
+	return end, nil
+}
+
+// compilePattern returns the jumps taken when the value doesn't match.
+func (c *Compiler) compilePattern(pattern ast.Expression, value int) ([]int, error) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value == "_" {
+			return nil, nil
+		}
+
+		err := c.emit(code.GET, value)
+		if err != nil {
+			return nil, err
+		}
+
+		sym, err := c.symbols.Define(pattern.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, c.emit(code.SET, sym.Index)
+	case *ast.Array, *ast.Map, *ast.CallExpression:
+		return nil, fmt.Errorf("pattern %s is not supported by the compiler yet", pattern.String())
+	default:
+		err := c.emit(code.GET, value)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.emit(code.EQ)
+		if err != nil {
+			return nil, err
+		}
+
+		next := len(c.instructions)
+
+		return []int{next}, c.emit(code.JCMP, 99999) // placeholder
+	}
+}
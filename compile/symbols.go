@@ -1,37 +1,106 @@
 package compile
 
+import "fmt"
+
 type SymbolScope string
 
 const (
 	GLOBAL SymbolScope = "GLOBAL"
+	LOCAL  SymbolScope = "LOCAL"
 )
 
 type Symbol struct {
-	Index int
-	Name  string
-	Scope SymbolScope
+	Index    int
+	Name     string
+	Scope    SymbolScope
+	Constant bool
 }
 
+// SymbolTable resolves names with the same rules as the evaluator's environments.
 type SymbolTable struct {
+	Outer *SymbolTable
+
 	symbols map[string]Symbol
+	// scope and size describe the slots the names are stored in, block scopes share them with their outer table.
+	scope SymbolScope
+	size  *int
 }
 
 func NewSymbolTable() *SymbolTable {
 	return &SymbolTable{
 		symbols: make(map[string]Symbol),
+		scope:   GLOBAL,
+		size:    new(int),
 	}
 }
 
-func (s *SymbolTable) Define(name string) Symbol {
+// NewEnclosedSymbolTable creates a local scope inside outer.
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	s.scope = LOCAL
+	return s
+}
+
+// NewBlockSymbolTable creates a scope for a block like a match case, its names are hidden
+// outside the block but stored in the same slots as the names of outer.
+func NewBlockSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	s.scope = outer.scope
+	s.size = outer.size
+	return s
+}
+
+// Define binds name in this scope, like `let`. Defining a name twice in the same scope reuses its symbol.
+func (s *SymbolTable) Define(name string) (Symbol, error) {
+	if symbol, ok := s.symbols[name]; ok {
+		if symbol.Constant {
+			return symbol, fmt.Errorf("can't assign to constant %s", name)
+		}
+		return symbol, nil
+	}
+
 	symbol := Symbol{
 		Name:  name,
-		Index: len(s.symbols),
-		Scope: GLOBAL,
+		Index: *s.size,
+		Scope: s.scope,
+	}
+	*s.size++
+	s.symbols[name] = symbol
+	return symbol, nil
+}
+
+// DefineConst binds name in this scope as a constant.
+func (s *SymbolTable) DefineConst(name string) (Symbol, error) {
+	symbol, err := s.Define(name)
+	if err != nil {
+		return symbol, err
 	}
+
+	symbol.Constant = true
 	s.symbols[name] = symbol
-	return symbol
+	return symbol, nil
 }
+
+// Resolve finds the nearest binding of name.
 func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
 	symbol, ok := s.symbols[name]
+
+	if !ok && s.Outer != nil {
+		return s.Outer.Resolve(name)
+	}
+
 	return symbol, ok
 }
+
+// Assign resolves the target of a plain assignment, the nearest binding of name or a new one in this scope.
+func (s *SymbolTable) Assign(name string) (Symbol, error) {
+	if symbol, ok := s.Resolve(name); ok {
+		if symbol.Constant {
+			return symbol, fmt.Errorf("can't assign to constant %s", name)
+		}
+		return symbol, nil
+	}
+	return s.Define(name)
+}
@@ -0,0 +1,82 @@
+package compile
+
+import "testing"
+
+func TestSymbolScopes(t *testing.T) {
+	global := NewSymbolTable()
+	a, _ := global.Define("a")
+	limit, _ := global.DefineConst("LIMIT")
+
+	local := NewEnclosedSymbolTable(global)
+
+	assign := func(name string) Symbol {
+		symbol, err := local.Assign(name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return symbol
+	}
+	define := func(table *SymbolTable, name string) Symbol {
+		symbol, err := table.Define(name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return symbol
+	}
+
+	table := []struct {
+		symbol   Symbol
+		expected Symbol
+	}{
+		{assign("a"), a},
+		{assign("b"), Symbol{Name: "b", Index: 0, Scope: LOCAL}},
+		{define(local, "a"), Symbol{Name: "a", Index: 1, Scope: LOCAL}},
+		{assign("a"), Symbol{Name: "a", Index: 1, Scope: LOCAL}},
+		{define(local, "LIMIT"), Symbol{Name: "LIMIT", Index: 2, Scope: LOCAL}},
+		{define(global, "a"), a},
+		{limit, Symbol{Name: "LIMIT", Index: 1, Scope: GLOBAL, Constant: true}},
+	}
+
+	for _, tt := range table {
+		if tt.symbol != tt.expected {
+			t.Errorf("Expected %+v, got %+v", tt.expected, tt.symbol)
+		}
+	}
+}
+
+func TestConstantSymbols(t *testing.T) {
+	global := NewSymbolTable()
+	global.DefineConst("LIMIT")
+
+	local := NewEnclosedSymbolTable(global)
+
+	if _, err := local.Assign("LIMIT"); err == nil {
+		t.Errorf("Expected an error assigning to a constant from an inner scope")
+	}
+	if _, err := global.Define("LIMIT"); err == nil {
+		t.Errorf("Expected an error redefining a constant")
+	}
+	if _, err := global.DefineConst("LIMIT"); err == nil {
+		t.Errorf("Expected an error redeclaring a constant")
+	}
+}
+
+func TestBlockSymbols(t *testing.T) {
+	global := NewSymbolTable()
+	n, _ := global.Define("n")
+
+	block := NewBlockSymbolTable(global)
+	inner, _ := block.Define("n")
+
+	if inner.Index == n.Index || inner.Scope != GLOBAL {
+		t.Errorf("Expected a new global slot for n in the block, got %+v", inner)
+	}
+
+	if symbol, _ := global.Resolve("n"); symbol != n {
+		t.Errorf("Expected the block's n to be hidden outside it, got %+v", symbol)
+	}
+
+	if next, _ := global.Define("m"); next.Index == inner.Index {
+		t.Errorf("Expected names defined after the block not to reuse its slots, got %+v", next)
+	}
+}
@@ -0,0 +1,507 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pspiagicw/fener/argparse"
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/eval"
+	"github.com/pspiagicw/fener/help"
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/parser"
+	"github.com/pspiagicw/fener/wire"
+	"github.com/pspiagicw/goreland"
+)
+
+type handler func(a *Adapter, args json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":        (*Adapter).initialize,
+	"launch":            (*Adapter).launch,
+	"setBreakpoints":    (*Adapter).setBreakpoints,
+	"configurationDone": (*Adapter).configurationDone,
+	"threads":           (*Adapter).threads,
+	"stackTrace":        (*Adapter).stackTrace,
+	"scopes":            (*Adapter).scopes,
+	"variables":         (*Adapter).variables,
+	"continue":          resume((*Debugger).Continue),
+	"next":              resume((*Debugger).StepOver),
+	"stepIn":            resume((*Debugger).StepIn),
+	"stepOut":           resume((*Debugger).StepOut),
+	"pause":             (*Adapter).pause,
+	"terminate":         (*Adapter).terminate,
+	"disconnect":        (*Adapter).terminate,
+}
+
+// Adapter serves a Debugger over the Debug Adapter Protocol.
+type Adapter struct {
+	in *bufio.Reader
+
+	mu  sync.Mutex
+	out io.Writer
+	seq int
+
+	// Program is the file to debug, a launch request can override it.
+	Program string
+	// Capabilities are granted to the builtins of the program.
+	Capabilities object.Capabilities
+
+	eval     *eval.Evaluator
+	debugger *Debugger
+	program  *ast.Program
+	env      *object.Environment
+
+	launched   bool
+	configured bool
+	started    bool
+	done       chan struct{}
+
+	// references are handed out for expandable values, they are only valid while stopped.
+	references []interface{}
+}
+
+func NewAdapter(in io.Reader, out io.Writer) *Adapter {
+	a := &Adapter{
+		in:           bufio.NewReader(in),
+		out:          out,
+		Capabilities: object.NewCapabilities(),
+		done:         make(chan struct{}),
+	}
+
+	a.eval = eval.New(func(err error) {
+		a.output("stderr", err.Error()+"\n")
+		a.debugger.Fail(err)
+	})
+
+	a.debugger = New(a.eval)
+	a.debugger.OnStop = func(stop *Stop) {
+		a.event("stopped", &StoppedEvent{Reason: stop.Reason, Description: stop.Description, Text: stop.Description, ThreadID: threadID, AllThreadsStopped: true})
+	}
+
+	return a
+}
+
+// Run serves requests until the client disconnects.
+func (a *Adapter) Run() error {
+	for {
+		body, err := wire.Read(a.in)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		req := &request{}
+
+		if err := json.Unmarshal(body, req); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+
+		if err := a.dispatch(req); err != nil {
+			return err
+		}
+
+		if req.Command == "disconnect" {
+			return nil
+		}
+	}
+}
+
+func (a *Adapter) dispatch(req *request) error {
+	resp := &response{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: true}
+
+	h, ok := handlers[req.Command]
+
+	if !ok {
+		resp.Success = false
+		resp.Message = fmt.Sprintf("unsupported request %s", req.Command)
+		return a.send(resp)
+	}
+
+	body, err := h(a, req.Arguments)
+
+	if err != nil {
+		resp.Success = false
+		resp.Message = err.Error()
+	} else {
+		resp.Body = body
+	}
+
+	if err := a.send(resp); err != nil {
+		return err
+	}
+
+	// The client configures breakpoints once it sees the initialized event.
+	if req.Command == "initialize" {
+		return a.event("initialized", nil)
+	}
+
+	return nil
+}
+
+func (a *Adapter) send(msg interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.seq++
+
+	switch msg := msg.(type) {
+	case *response:
+		msg.Seq = a.seq
+	case *event:
+		msg.Seq = a.seq
+	}
+
+	body, err := json.Marshal(msg)
+
+	if err != nil {
+		return err
+	}
+
+	return wire.Write(a.out, body)
+}
+
+func (a *Adapter) event(name string, body interface{}) error {
+	return a.send(&event{Type: "event", Event: name, Body: body})
+}
+
+func (a *Adapter) output(category string, text string) {
+	a.event("output", &OutputEvent{Category: category, Output: text})
+}
+
+// outputWriter forwards the program's output to the client.
+type outputWriter struct {
+	adapter  *Adapter
+	category string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.adapter.output(w.category, string(p))
+	return len(p), nil
+}
+
+func (a *Adapter) initialize(args json.RawMessage) (interface{}, error) {
+	return &Capabilities{SupportsConfigurationDoneRequest: true, SupportsTerminateRequest: true}, nil
+}
+
+func (a *Adapter) launch(args json.RawMessage) (interface{}, error) {
+	launch := &LaunchArguments{}
+
+	if err := json.Unmarshal(args, launch); err != nil {
+		return nil, err
+	}
+
+	if launch.Program != "" {
+		a.Program = launch.Program
+	}
+
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+
+	if launch.StopOnEntry {
+		a.debugger.StopOnEntry()
+	}
+
+	a.launched = true
+
+	return nil, a.start()
+}
+
+func (a *Adapter) load() error {
+	if a.Program == "" {
+		return fmt.Errorf("no program to debug")
+	}
+
+	contents, err := os.ReadFile(a.Program)
+
+	if err != nil {
+		return err
+	}
+
+	p := parser.New(lexer.New(string(contents)))
+	a.program = p.Parse()
+
+	if len(p.Diagnostics()) > 0 {
+		first := p.Diagnostics()[0]
+		return fmt.Errorf("%s:%d: %s", a.Program, first.Line+1, first.Message)
+	}
+
+	a.env = object.NewSandboxedEnvironment(a.Capabilities)
+	a.env.Stdout = &outputWriter{adapter: a, category: "stdout"}
+	a.env.Stderr = &outputWriter{adapter: a, category: "stderr"}
+	a.eval.File = a.Program
+
+	return nil
+}
+
+func (a *Adapter) configurationDone(args json.RawMessage) (interface{}, error) {
+	a.configured = true
+
+	return nil, a.start()
+}
+
+// start runs the program once it is launched and every breakpoint is set.
+func (a *Adapter) start() error {
+	if !a.launched || !a.configured || a.started {
+		return nil
+	}
+
+	a.started = true
+
+	go func() {
+		defer close(a.done)
+
+		exitCode := 0
+		if !a.debugger.Run(a.program, a.env) {
+			exitCode = 1
+		}
+
+		a.event("exited", &ExitedEvent{ExitCode: exitCode})
+		a.event("terminated", nil)
+	}()
+
+	return nil
+}
+
+func (a *Adapter) setBreakpoints(args json.RawMessage) (interface{}, error) {
+	params := &SetBreakpointsArguments{}
+
+	if err := json.Unmarshal(args, params); err != nil {
+		return nil, err
+	}
+
+	lines := []int{}
+	breakpoints := []Breakpoint{}
+
+	for _, bp := range params.Breakpoints {
+		lines = append(lines, bp.Line)
+		breakpoints = append(breakpoints, Breakpoint{Verified: true, Line: bp.Line})
+	}
+
+	a.debugger.SetBreakpoints(lines)
+
+	return &SetBreakpointsResponse{Breakpoints: breakpoints}, nil
+}
+
+func (a *Adapter) threads(args json.RawMessage) (interface{}, error) {
+	return &ThreadsResponse{Threads: []Thread{{ID: threadID, Name: "main"}}}, nil
+}
+
+func (a *Adapter) stopped() error {
+	if !a.debugger.Stopped() {
+		return fmt.Errorf("the program is running")
+	}
+	return nil
+}
+
+func (a *Adapter) stackTrace(args json.RawMessage) (interface{}, error) {
+	if err := a.stopped(); err != nil {
+		return nil, err
+	}
+
+	source := &Source{Name: filepath.Base(a.Program), Path: a.Program}
+	frames := []StackFrame{}
+
+	for i, frame := range a.debugger.Frames() {
+		frames = append(frames, StackFrame{ID: i, Name: frame.Name, Source: source, Line: Line(frame.Statement), Column: 1})
+	}
+
+	return &StackTraceResponse{StackFrames: frames, TotalFrames: len(frames)}, nil
+}
+
+func (a *Adapter) scopes(args json.RawMessage) (interface{}, error) {
+	params := &ScopesArguments{}
+
+	if err := json.Unmarshal(args, params); err != nil {
+		return nil, err
+	}
+
+	if err := a.stopped(); err != nil {
+		return nil, err
+	}
+
+	frames := a.debugger.Frames()
+
+	if params.FrameID < 0 || params.FrameID >= len(frames) {
+		return nil, fmt.Errorf("unknown frame %d", params.FrameID)
+	}
+
+	scopes := []Scope{}
+
+	for env := frames[params.FrameID].Env; env != nil; env = env.Outer {
+		name := "Closure"
+
+		switch {
+		case env.Outer == nil:
+			name = "Globals"
+		case len(scopes) == 0:
+			name = "Locals"
+		}
+
+		scopes = append(scopes, Scope{Name: name, VariablesReference: a.reference(env)})
+	}
+
+	return &ScopesResponse{Scopes: scopes}, nil
+}
+
+func (a *Adapter) reference(value interface{}) int {
+	a.references = append(a.references, value)
+	return len(a.references)
+}
+
+func (a *Adapter) variables(args json.RawMessage) (interface{}, error) {
+	params := &VariablesArguments{}
+
+	if err := json.Unmarshal(args, params); err != nil {
+		return nil, err
+	}
+
+	if err := a.stopped(); err != nil {
+		return nil, err
+	}
+
+	if params.VariablesReference < 1 || params.VariablesReference > len(a.references) {
+		return nil, fmt.Errorf("unknown variables reference %d", params.VariablesReference)
+	}
+
+	variables := []Variable{}
+
+	switch value := a.references[params.VariablesReference-1].(type) {
+	case *object.Environment:
+		for _, name := range sortedKeys(value.Bindings) {
+			if _, ok := value.Bindings[name].(*object.Builtin); ok {
+				continue
+			}
+			variables = append(variables, a.variable(name, value.Bindings[name]))
+		}
+	case *object.Array:
+		for i, element := range value.Elements {
+			variables = append(variables, a.variable(fmt.Sprintf("[%d]", i+1), element))
+		}
+	case *object.Map:
+		for _, pair := range value.Items() {
+			variables = append(variables, a.variable(display(pair.Key), pair.Value))
+		}
+	case *object.Instance:
+		for _, name := range sortedKeys(value.Map) {
+			variables = append(variables, a.variable(name, value.Map[name]))
+		}
+	}
+
+	return &VariablesResponse{Variables: variables}, nil
+}
+
+func (a *Adapter) variable(name string, value object.Object) Variable {
+	v := Variable{Name: name, Value: display(value)}
+
+	if value == nil {
+		return v
+	}
+
+	v.Type = string(value.Type())
+
+	switch value.(type) {
+	case *object.Array, *object.Map, *object.Instance:
+		v.VariablesReference = a.reference(value)
+	}
+
+	return v
+}
+
+func display(value object.Object) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case *object.String:
+		return strconv.Quote(value.Value)
+	default:
+		return value.Pretty()
+	}
+}
+
+func sortedKeys(m map[string]object.Object) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resume wraps a debugger command, the references are dropped since the values may change.
+func resume(command func(*Debugger) bool) handler {
+	return func(a *Adapter, args json.RawMessage) (interface{}, error) {
+		a.references = nil
+
+		if !command(a.debugger) {
+			return nil, fmt.Errorf("the program is not stopped")
+		}
+
+		return &ContinueResponse{AllThreadsContinued: true}, nil
+	}
+}
+
+func (a *Adapter) pause(args json.RawMessage) (interface{}, error) {
+	a.debugger.Pause()
+	return nil, nil
+}
+
+func (a *Adapter) terminate(args json.RawMessage) (interface{}, error) {
+	if a.started {
+		a.references = nil
+		a.debugger.Terminate()
+	}
+	return nil, nil
+}
+
+func parseDebugArgs(opts *argparse.Opts) {
+	flag := flag.NewFlagSet("fener debug", flag.ExitOnError)
+
+	flag.Usage = help.Debug
+
+	flag.Func("allow", "Capabilities granted to the program", func(value string) error {
+		opts.Allow = append(opts.Allow, strings.Split(value, ",")...)
+		return nil
+	})
+
+	flag.Parse(opts.Args)
+
+	opts.Args = flag.Args()
+}
+
+func Entry(opts *argparse.Opts) {
+	parseDebugArgs(opts)
+
+	a := NewAdapter(os.Stdin, os.Stdout)
+
+	caps, err := object.ParseCapabilities(opts.Allow)
+
+	if err != nil {
+		goreland.LogFatal("Invalid --allow flag: %v", err)
+	}
+
+	a.Capabilities = caps
+
+	if len(opts.Args) > 0 {
+		a.Program = opts.Args[0]
+	}
+
+	if err := a.Run(); err != nil {
+		goreland.LogFatal("Debug adapter failed: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pspiagicw/fener/wire"
+)
+
+type message struct {
+	Type       string          `json:"type"`
+	Event      string          `json:"event"`
+	Command    string          `json:"command"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// client talks to an adapter running in the same process.
+type client struct {
+	t        *testing.T
+	out      io.Writer
+	seq      int
+	messages chan *message
+	events   []*message
+}
+
+func newClient(t *testing.T, a func(in io.Reader, out io.Writer) *Adapter) *client {
+	adapterIn, clientOut := io.Pipe()
+	clientIn, adapterOut := io.Pipe()
+
+	c := &client{t: t, out: clientOut, messages: make(chan *message, 64)}
+
+	go func() {
+		a(adapterIn, adapterOut).Run()
+		adapterOut.Close()
+	}()
+
+	go func() {
+		in := bufio.NewReader(clientIn)
+		defer close(c.messages)
+
+		for {
+			body, err := wire.Read(in)
+			if err != nil {
+				return
+			}
+
+			msg := &message{}
+			json.Unmarshal(body, msg)
+			c.messages <- msg
+		}
+	}()
+
+	return c
+}
+
+func (c *client) next() *message {
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatalf("Adapter closed the connection")
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("Timed out waiting for the adapter")
+		return nil
+	}
+}
+
+func (c *client) request(command string, args interface{}, body interface{}) {
+	c.t.Helper()
+
+	c.seq++
+	payload, _ := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": args})
+	wire.Write(c.out, payload)
+
+	for {
+		msg := c.next()
+
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+
+		if msg.RequestSeq != c.seq || !msg.Success {
+			c.t.Fatalf("%s failed: %+v", command, msg)
+		}
+
+		if body != nil {
+			json.Unmarshal(msg.Body, body)
+		}
+
+		return
+	}
+}
+
+// event waits for an event, skipping earlier ones.
+func (c *client) event(name string, body interface{}) {
+	c.t.Helper()
+
+	for i, msg := range c.events {
+		if msg.Event == name {
+			c.events = c.events[i+1:]
+			json.Unmarshal(msg.Body, body)
+			return
+		}
+	}
+
+	c.events = nil
+
+	for {
+		msg := c.next()
+		if msg.Type == "event" && msg.Event == name {
+			json.Unmarshal(msg.Body, body)
+			return
+		}
+	}
+}
+
+func TestAdapter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.fn")
+	os.WriteFile(file, []byte(`fn greet(name)
+ message = "hello " 
+ print(name)
+ return [1, 2]
+end
+people = {"ada" = 36}
+greet("ada")
+`), 0644)
+
+	c := newClient(t, NewAdapter)
+
+	c.request("initialize", map[string]interface{}{"adapterID": "fener"}, nil)
+	c.event("initialized", &struct{}{})
+
+	breakpoints := &SetBreakpointsResponse{}
+	c.request("setBreakpoints", &SetBreakpointsArguments{Source: Source{Path: file}, Breakpoints: []SourceBreakpoint{{Line: 3}}}, breakpoints)
+
+	if len(breakpoints.Breakpoints) != 1 || !breakpoints.Breakpoints[0].Verified {
+		t.Fatalf("Expected a verified breakpoint, got %+v", breakpoints)
+	}
+
+	c.request("launch", &LaunchArguments{Program: file}, nil)
+	c.request("configurationDone", nil, nil)
+
+	stopped := &StoppedEvent{}
+	c.event("stopped", stopped)
+
+	if stopped.Reason != "breakpoint" {
+		t.Fatalf("Expected to stop on a breakpoint, got %+v", stopped)
+	}
+
+	trace := &StackTraceResponse{}
+	c.request("stackTrace", map[string]int{"threadId": threadID}, trace)
+
+	if len(trace.StackFrames) != 2 || trace.StackFrames[0].Name != "greet" || trace.StackFrames[0].Line != 3 || trace.StackFrames[1].Line != 7 {
+		t.Fatalf("Unexpected stack trace %+v", trace.StackFrames)
+	}
+
+	scopes := &ScopesResponse{}
+	c.request("scopes", &ScopesArguments{FrameID: 0}, scopes)
+
+	if len(scopes.Scopes) != 2 || scopes.Scopes[0].Name != "Locals" || scopes.Scopes[1].Name != "Globals" {
+		t.Fatalf("Unexpected scopes %+v", scopes.Scopes)
+	}
+
+	locals := &VariablesResponse{}
+	c.request("variables", &VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference}, locals)
+
+	expected := []Variable{
+		{Name: "message", Value: `"hello "`, Type: "STRING"},
+		{Name: "name", Value: `"ada"`, Type: "STRING"},
+	}
+
+	if len(locals.Variables) != len(expected) {
+		t.Fatalf("Expected locals %+v, got %+v", expected, locals.Variables)
+	}
+
+	for i, v := range expected {
+		if locals.Variables[i] != v {
+			t.Errorf("Expected local %+v, got %+v", v, locals.Variables[i])
+		}
+	}
+
+	globals := &VariablesResponse{}
+	c.request("variables", &VariablesArguments{VariablesReference: scopes.Scopes[1].VariablesReference}, globals)
+
+	var people *Variable
+	for i := range globals.Variables {
+		if globals.Variables[i].Name == "people" {
+			people = &globals.Variables[i]
+		}
+	}
+
+	if people == nil || people.VariablesReference == 0 {
+		t.Fatalf("Expected an expandable people map, got %+v", globals.Variables)
+	}
+
+	pairs := &VariablesResponse{}
+	c.request("variables", &VariablesArguments{VariablesReference: people.VariablesReference}, pairs)
+
+	if len(pairs.Variables) != 1 || pairs.Variables[0].Name != `"ada"` || pairs.Variables[0].Value != "36" {
+		t.Errorf("Unexpected map entries %+v", pairs.Variables)
+	}
+
+	c.request("next", map[string]int{"threadId": threadID}, nil)
+
+	output := &OutputEvent{}
+	c.event("output", output)
+
+	if output.Category != "stdout" || output.Output != "ada" {
+		t.Errorf("Expected program output, got %+v", output)
+	}
+
+	c.event("stopped", stopped)
+
+	c.request("continue", map[string]int{"threadId": threadID}, nil)
+
+	exited := &ExitedEvent{}
+	c.event("exited", exited)
+
+	if exited.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %d", exited.ExitCode)
+	}
+
+	c.request("disconnect", nil, nil)
+}
+
+func TestAdapterRuntimeError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.fn")
+	os.WriteFile(file, []byte("x = 1\ny = missing + 1\n"), 0644)
+
+	c := newClient(t, NewAdapter)
+
+	c.request("initialize", nil, nil)
+	c.request("launch", &LaunchArguments{Program: file}, nil)
+	c.request("configurationDone", nil, nil)
+
+	stopped := &StoppedEvent{}
+	c.event("stopped", stopped)
+
+	if stopped.Reason != "exception" || stopped.Description != "Identifier not found: missing" {
+		t.Fatalf("Expected to stop on the error, got %+v", stopped)
+	}
+
+	c.request("continue", map[string]int{"threadId": threadID}, nil)
+
+	exited := &ExitedEvent{}
+	c.event("exited", exited)
+
+	if exited.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", exited.ExitCode)
+	}
+
+	c.request("disconnect", nil, nil)
+}
@@ -0,0 +1,282 @@
+// Package debug implements a debugger for the tree walking evaluator and serves it
+// over the Debug Adapter Protocol.
+package debug
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/eval"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/token"
+)
+
+type mode int
+
+const (
+	running mode = iota
+	entry
+	stepIn
+	stepOver
+	stepOut
+	pause
+	terminate
+)
+
+// errTerminated unwinds the evaluator when the session ends before the program does.
+var errTerminated = errors.New("program terminated")
+
+// Stop describes why execution paused.
+type Stop struct {
+	Reason      string
+	Line        int
+	Description string
+}
+
+// Debugger pauses an evaluator on breakpoints and steps, lines start at 1.
+type Debugger struct {
+	eval *eval.Evaluator
+
+	// OnStop is called on the evaluating goroutine every time execution pauses,
+	// the evaluator waits until Continue or a step is requested.
+	OnStop func(*Stop)
+
+	mu          sync.Mutex
+	breakpoints map[int]bool
+	mode        mode
+	depth       int
+	stopped     bool
+
+	// The location of the previous statement, a line is only stopped on once.
+	line      int
+	lineDepth int
+
+	resume chan mode
+}
+
+func New(e *eval.Evaluator) *Debugger {
+	d := &Debugger{
+		eval:        e,
+		breakpoints: map[int]bool{},
+		resume:      make(chan mode),
+		OnStop:      func(*Stop) {},
+	}
+
+	e.Hooks = append(e.Hooks, d.hook)
+
+	return d
+}
+
+// StopOnEntry pauses before the first statement.
+func (d *Debugger) StopOnEntry() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.mode = entry
+}
+
+// SetBreakpoints replaces every breakpoint.
+func (d *Debugger) SetBreakpoints(lines []int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.breakpoints = map[int]bool{}
+	for _, line := range lines {
+		d.breakpoints[line] = true
+	}
+}
+
+// Stopped reports whether the evaluator is waiting.
+func (d *Debugger) Stopped() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.stopped
+}
+
+func (d *Debugger) Continue() bool { return d.send(running) }
+func (d *Debugger) StepIn() bool   { return d.send(stepIn) }
+func (d *Debugger) StepOver() bool { return d.send(stepOver) }
+func (d *Debugger) StepOut() bool  { return d.send(stepOut) }
+
+// Pause stops before the next statement.
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.stopped {
+		d.mode = pause
+	}
+}
+
+// Terminate ends the program at the next statement, or right away if stopped.
+func (d *Debugger) Terminate() {
+	if d.send(terminate) {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.mode = terminate
+}
+
+// send resumes a stopped evaluator, it does nothing while running.
+func (d *Debugger) send(m mode) bool {
+	if !d.Stopped() {
+		return false
+	}
+
+	d.resume <- m
+
+	return true
+}
+
+// Fail pauses on a runtime error, the program is terminated once resumed.
+func (d *Debugger) Fail(err error) {
+	d.wait(&Stop{Reason: "exception", Line: d.currentLine(), Description: err.Error()}, len(d.eval.Frames()))
+	panic(errTerminated)
+}
+
+// Frames returns the calls in progress, innermost first.
+func (d *Debugger) Frames() []eval.Frame {
+	frames := d.eval.Frames()
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+
+	return frames
+}
+
+func (d *Debugger) currentLine() int {
+	frames := d.Frames()
+
+	if len(frames) == 0 {
+		return 0
+	}
+
+	return Line(frames[0].Statement)
+}
+
+func (d *Debugger) hook(node ast.Statement, env *object.Environment) {
+	line := Line(node)
+	depth := len(d.eval.Frames())
+
+	d.mu.Lock()
+
+	newLine := line != d.line || depth != d.lineDepth
+	d.line, d.lineDepth = line, depth
+
+	reason := d.reason(line, depth, newLine)
+	terminated := d.mode == terminate
+
+	d.mu.Unlock()
+
+	if terminated {
+		panic(errTerminated)
+	}
+
+	if reason == "" {
+		return
+	}
+
+	d.wait(&Stop{Reason: reason, Line: line}, depth)
+}
+
+func (d *Debugger) reason(line int, depth int, newLine bool) string {
+	switch {
+	case d.mode == pause:
+		return "pause"
+	case !newLine:
+		return ""
+	case d.mode == entry:
+		return "entry"
+	case d.mode == stepIn:
+		return "step"
+	case d.mode == stepOver && depth <= d.depth:
+		return "step"
+	case d.mode == stepOut && depth < d.depth:
+		return "step"
+	case d.breakpoints[line]:
+		return "breakpoint"
+	}
+
+	return ""
+}
+
+// wait blocks the evaluator until it is resumed.
+func (d *Debugger) wait(stop *Stop, depth int) {
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+
+	d.OnStop(stop)
+
+	m := <-d.resume
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = false
+	d.mode = m
+	d.depth = depth
+
+	if m == terminate {
+		panic(errTerminated)
+	}
+}
+
+// Run evaluates program, it returns false if the debugger terminated it.
+func (d *Debugger) Run(program *ast.Program, env *object.Environment) (finished bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errTerminated {
+				panic(r)
+			}
+			finished = false
+		}
+	}()
+
+	d.eval.Eval(program, env)
+
+	return true
+}
+
+// Line returns the line a statement starts on, counting from 1.
+func Line(node ast.Statement) int {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		return line(node.Token)
+	case *ast.ReturnStatement:
+		return line(node.Token)
+	case *ast.LetStatement:
+		return line(node.Token)
+	case *ast.ConstStatement:
+		return line(node.Token)
+	case *ast.WhileStatement:
+		return line(node.Token)
+	case *ast.FunctionStatement:
+		return line(node.Token)
+	case *ast.ClassStatement:
+		return line(node.Token)
+	case *ast.TraitStatement:
+		return line(node.Token)
+	case *ast.TestStatement:
+		return line(node.Token)
+	case *ast.ImportStatement:
+		return line(node.Token)
+	case *ast.BlockStatement:
+		return line(node.Token)
+	}
+
+	return 0
+}
+
+func line(t *token.Token) int {
+	if t == nil {
+		return 0
+	}
+	return t.Line + 1
+}
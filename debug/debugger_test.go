@@ -0,0 +1,181 @@
+package debug
+
+import (
+	"testing"
+
+	"github.com/pspiagicw/fener/eval"
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/parser"
+)
+
+const program = `fn add(a, b)
+ sum = a + b
+ return sum
+end
+x = 1
+y = add(x, 2)
+z = y * 2
+`
+
+type session struct {
+	t        *testing.T
+	debugger *Debugger
+	stops    chan *Stop
+	done     chan bool
+}
+
+func start(t *testing.T, input string, setup func(d *Debugger)) *session {
+	p := parser.New(lexer.New(input))
+	program := p.Parse()
+
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	e := eval.New(func(err error) { t.Errorf("Unexpected error: %v", err) })
+
+	s := &session{t: t, debugger: New(e), stops: make(chan *Stop), done: make(chan bool, 1)}
+	s.debugger.OnStop = func(stop *Stop) { s.stops <- stop }
+
+	setup(s.debugger)
+
+	go func() {
+		s.done <- s.debugger.Run(program, object.NewEnvironment())
+		close(s.stops)
+	}()
+
+	return s
+}
+
+// expect waits for the next stop and checks where it happened.
+func (s *session) expect(reason string, line int, function string) {
+	s.t.Helper()
+
+	stop, ok := <-s.stops
+
+	if !ok {
+		s.t.Fatalf("Expected to stop at line %d, the program finished", line)
+	}
+
+	if stop.Reason != reason || stop.Line != line {
+		s.t.Fatalf("Expected %s stop at line %d, got %s at %d", reason, line, stop.Reason, stop.Line)
+	}
+
+	if frames := s.debugger.Frames(); frames[0].Name != function {
+		s.t.Fatalf("Expected to be in %s, got %s", function, frames[0].Name)
+	}
+}
+
+func (s *session) finish() {
+	s.t.Helper()
+
+	if stop, ok := <-s.stops; ok {
+		s.t.Fatalf("Expected the program to finish, stopped at %+v", stop)
+	}
+
+	if !<-s.done {
+		s.t.Fatalf("Expected the program to run to completion")
+	}
+}
+
+func TestBreakpoints(t *testing.T) {
+	s := start(t, program, func(d *Debugger) {
+		d.SetBreakpoints([]int{2, 7})
+	})
+
+	s.expect("breakpoint", 2, "add")
+
+	frames := s.debugger.Frames()
+	if len(frames) != 2 || frames[1].Name != "main" {
+		t.Fatalf("Expected add called from main, got %+v", frames)
+	}
+
+	if a := frames[0].Env.Get("a"); a.String() != "int(1)" {
+		t.Errorf("Expected a to be int(1), got %v", a)
+	}
+
+	// Globals are reachable through the outer environments.
+	if x := frames[0].Env.Outer.Get("x"); x.String() != "int(1)" {
+		t.Errorf("Expected x to be int(1), got %v", x)
+	}
+
+	s.debugger.Continue()
+	s.expect("breakpoint", 7, "main")
+
+	s.debugger.Continue()
+	s.finish()
+}
+
+func TestStepping(t *testing.T) {
+	s := start(t, program, func(d *Debugger) {
+		d.StopOnEntry()
+	})
+
+	s.expect("entry", 1, "main")
+
+	s.debugger.StepOver()
+	s.expect("step", 5, "main")
+
+	s.debugger.StepOver()
+	s.expect("step", 6, "main")
+
+	s.debugger.StepIn()
+	s.expect("step", 2, "add")
+
+	s.debugger.StepOut()
+	s.expect("step", 7, "main")
+
+	s.debugger.Continue()
+	s.finish()
+}
+
+func TestStepOverCall(t *testing.T) {
+	s := start(t, program, func(d *Debugger) {
+		d.SetBreakpoints([]int{6})
+	})
+
+	s.expect("breakpoint", 6, "main")
+
+	s.debugger.StepOver()
+	s.expect("step", 7, "main")
+
+	s.debugger.Continue()
+	s.finish()
+}
+
+func TestDeclarationBreakpoints(t *testing.T) {
+	s := start(t, "x = 1\nlet y = x + 1\nconst z = y\nw = z\n", func(d *Debugger) {
+		d.SetBreakpoints([]int{2, 3})
+	})
+
+	s.expect("breakpoint", 2, "main")
+
+	if y := s.debugger.Frames()[0].Env.Get("y"); y != nil {
+		t.Errorf("Expected y to be unset before its declaration, got %v", y)
+	}
+
+	s.debugger.Continue()
+	s.expect("breakpoint", 3, "main")
+
+	s.debugger.Continue()
+	s.finish()
+}
+
+func TestTerminate(t *testing.T) {
+	s := start(t, "i = 0\nwhile true then\n i = i + 1\nend\n", func(d *Debugger) {
+		d.SetBreakpoints([]int{3})
+	})
+
+	s.expect("breakpoint", 3, "main")
+
+	if i := s.debugger.Frames()[0].Env.Get("i"); i.String() != "int(0)" {
+		t.Errorf("Expected i to be int(0), got %v", i)
+	}
+
+	s.debugger.Terminate()
+
+	if <-s.done {
+		t.Fatalf("Expected the program to be terminated")
+	}
+}
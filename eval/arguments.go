@@ -0,0 +1,120 @@
+package eval
+
+import (
+	"strings"
+
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/object"
+)
+
+// keywordArgument is an evaluated `name: value` argument.
+type keywordArgument struct {
+	name  string
+	value object.Object
+}
+
+func (e *Evaluator) evalCallArgs(args []ast.Expression, env *object.Environment) ([]object.Object, []*keywordArgument) {
+	var positional []object.Object
+	var keywords []*keywordArgument
+
+	for _, arg := range args {
+		if keyword, ok := arg.(*ast.KeywordArgument); ok {
+			keywords = append(keywords, &keywordArgument{name: keyword.Key, value: e.Eval(keyword.Value, env)})
+			continue
+		}
+		positional = append(positional, e.Eval(arg, env))
+	}
+
+	return positional, keywords
+}
+
+func functionName(fn *object.Function) string {
+	if fn.Name == "" {
+		return "<lambda>"
+	}
+	return fn.Name
+}
+
+// bindArguments binds the parameters of fn in env, defaults are evaluated in env after the given arguments are bound.
+func (e *Evaluator) bindArguments(fn *object.Function, args []object.Object, keywords []*keywordArgument, env *object.Environment) bool {
+	name := functionName(fn)
+
+	if len(args) > len(fn.Arguments) && fn.Rest == "" {
+		e.Error("%s() takes %d arguments, got %d", name, len(fn.Arguments), len(args))
+		return false
+	}
+
+	bound := map[string]bool{}
+
+	for i, param := range fn.Arguments {
+		if i >= len(args) {
+			break
+		}
+		env.Set(param, args[i])
+		bound[param] = true
+	}
+
+	for _, keyword := range keywords {
+		if !isParameter(fn, keyword.name) {
+			e.Error("%s() got an unexpected keyword argument %s", name, keyword.name)
+			return false
+		}
+		if bound[keyword.name] {
+			e.Error("%s() got multiple values for argument %s", name, keyword.name)
+			return false
+		}
+		env.Set(keyword.name, keyword.value)
+		bound[keyword.name] = true
+	}
+
+	missing := []string{}
+
+	for _, param := range fn.Arguments {
+		if bound[param] {
+			continue
+		}
+		if value, ok := fn.Defaults[param]; ok {
+			evaluated := e.Eval(value, env)
+			if evaluated == nil {
+				return false
+			}
+			env.Set(param, evaluated)
+			continue
+		}
+		missing = append(missing, param)
+	}
+
+	if len(missing) == 1 {
+		e.Error("%s() missing argument %s", name, missing[0])
+		return false
+	}
+	if len(missing) > 1 {
+		e.Error("%s() missing arguments %s", name, strings.Join(missing, ", "))
+		return false
+	}
+
+	if fn.Rest != "" {
+		rest := []object.Object{}
+		if len(args) > len(fn.Arguments) {
+			rest = append(rest, args[len(fn.Arguments):]...)
+		}
+		env.Set(fn.Rest, &object.Array{Elements: rest})
+	}
+
+	for _, param := range fn.Arguments {
+		if pattern, ok := fn.Patterns[param]; ok && !e.destructure(pattern, env.Get(param), env, true) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isParameter(fn *object.Function, name string) bool {
+	for _, param := range fn.Arguments {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
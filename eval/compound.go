@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/object"
+)
+
+// evalCompoundAssignment evaluates the parts of the target once, reading and assigning through them.
+func (e *Evaluator) evalCompoundAssignment(node *ast.CompoundAssignment, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		current := e.evalIdentifier(target, env)
+		result := e.compound(node, current, env)
+		if result == nil {
+			return nil
+		}
+
+		if err := env.Assign(target.Value, result); err != nil {
+			e.Error("%s", err)
+			return nil
+		}
+		return result
+	case *ast.FieldExpression:
+		obj := e.Eval(target.Target, env)
+		if obj == nil {
+			return nil
+		}
+
+		instance, ok := obj.(*object.Instance)
+		if !ok {
+			e.Error("Can't access field on non-instance object %T", obj)
+			return nil
+		}
+
+		current, ok := instance.Get(target.Field.Value)
+		if !ok {
+			e.Error("Field not found: %s", target.Field.Value)
+			return nil
+		}
+
+		result := e.compound(node, current, env)
+		if result == nil {
+			return nil
+		}
+		return e.setField(instance, target.Field.Value, result)
+	case *ast.IndexExpression:
+		left := e.Eval(target.Left, env)
+		index := e.Eval(target.Index, env)
+		if left == nil || index == nil {
+			return nil
+		}
+
+		current := e.index(left, index)
+		result := e.compound(node, current, env)
+		if result == nil {
+			return nil
+		}
+		return e.setIndex(left, index, result)
+	default:
+		e.Error("Can't assign to expression %T", target)
+		return nil
+	}
+}
+
+// compound applies the operator of node to the current value of the target and the value.
+func (e *Evaluator) compound(node *ast.CompoundAssignment, current object.Object, env *object.Environment) object.Object {
+	if current == nil {
+		return nil
+	}
+
+	value := e.Eval(node.Value, env)
+	if value == nil {
+		return nil
+	}
+
+	return e.arithmetic(node.Operator, current, value)
+}
@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/object"
+)
+
+// destructure assigns value to target, lists and maps assign their parts to the targets inside them.
+// Names are bound in env when define is set, like parameters, and assigned otherwise.
+func (e *Evaluator) destructure(target ast.Expression, value object.Object, env *object.Environment, define bool) bool {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		if define {
+			env.Set(target.Value, value)
+			return true
+		}
+		if err := env.Assign(target.Value, value); err != nil {
+			e.Error("%s", err)
+			return false
+		}
+		return true
+	case *ast.FieldExpression:
+		return e.assignField(target, value, env) != nil
+	case *ast.IndexExpression:
+		return e.assignIndex(target, value, env) != nil
+	case *ast.Array:
+		return e.destructureArray(target, value, env, define)
+	case *ast.Map:
+		return e.destructureMap(target, value, env, define)
+	default:
+		e.Error("Can't assign to expression %T", target)
+		return false
+	}
+}
+
+func (e *Evaluator) destructureArray(target *ast.Array, value object.Object, env *object.Environment, define bool) bool {
+	array, ok := value.(*object.Array)
+	if !ok {
+		e.Error("Can't destructure %s into a list", value.Type())
+		return false
+	}
+
+	if len(array.Elements) != len(target.Elements) {
+		e.Error("Expected %d values to destructure, got %d", len(target.Elements), len(array.Elements))
+		return false
+	}
+
+	for i, element := range target.Elements {
+		if !e.destructure(element, array.Elements[i], env, define) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *Evaluator) destructureMap(target *ast.Map, value object.Object, env *object.Environment, define bool) bool {
+	m, ok := value.(*object.Map)
+	if !ok {
+		e.Error("Can't destructure %s into a map", value.Type())
+		return false
+	}
+
+	for i, key := range target.Keys {
+		k := e.Eval(key, env)
+		if k == nil {
+			return false
+		}
+
+		v, ok := m.Get(k)
+		if !ok {
+			e.Error("Map has no key %s to destructure", k.String())
+			return false
+		}
+
+		if !e.destructure(target.Values[i], v, env, define) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,9 +1,13 @@
 package eval
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/budget"
 	"github.com/pspiagicw/fener/object"
 	"github.com/pspiagicw/fener/token"
 )
@@ -11,12 +15,36 @@ import (
 type Evaluator struct {
 	ErrorHandler func(error)
 	Test         bool
+
+	// File is the path of the file being evaluated, imports are resolved relative to it.
+	File   string
+	Loader *Loader
+
+	// Budget limits the number of evaluated nodes and the call depth, nil means unlimited.
+	Budget *budget.Budget
+
+	// Hooks run before every statement.
+	Hooks []Hook
+
+	frames []*Frame
+}
+
+// Hook is called with a statement and the environment it is about to be evaluated in.
+type Hook func(node ast.Statement, env *object.Environment)
+
+// Frame is a function call in progress.
+type Frame struct {
+	Name string
+	Env  *object.Environment
+	// Statement is the statement being evaluated in this frame.
+	Statement ast.Statement
 }
 
 func New(handler func(error)) *Evaluator {
 	return &Evaluator{
 		Test:         false,
 		ErrorHandler: handler,
+		Loader:       NewLoader(SearchPath()),
 	}
 }
 
@@ -26,29 +54,219 @@ func (e *Evaluator) Error(message string, args ...interface{}) {
 
 	e.ErrorHandler(err)
 }
+
+// step charges a node evaluation to the budget, the exceeded limit is only reported once.
+// Later nodes evaluate to null, Invoke returns the limit error so builtins don't use those nulls.
+func (e *Evaluator) step() bool {
+	if e.Budget == nil {
+		return true
+	}
+
+	if e.Budget.Err() != nil {
+		return false
+	}
+
+	if err := e.Budget.Step(); err != nil {
+		e.ErrorHandler(err)
+		return false
+	}
+
+	return true
+}
+func (e *Evaluator) enter() bool {
+	if e.Budget == nil {
+		return true
+	}
+
+	if e.Budget.Err() != nil {
+		return false
+	}
+
+	if err := e.Budget.Enter(); err != nil {
+		e.ErrorHandler(err)
+		return false
+	}
+
+	return true
+}
+func (e *Evaluator) leave() {
+	if e.Budget != nil {
+		e.Budget.Leave()
+	}
+}
+
+// Frames returns the calls in progress, the outermost first.
+func (e *Evaluator) Frames() []Frame {
+	frames := []Frame{}
+	for _, frame := range e.frames {
+		frames = append(frames, *frame)
+	}
+	return frames
+}
+func (e *Evaluator) pushFrame(name string, env *object.Environment) {
+	e.frames = append(e.frames, &Frame{Name: name, Env: env})
+}
+func (e *Evaluator) popFrame() {
+	e.frames = e.frames[:len(e.frames)-1]
+}
+
+// beforeStatement records the statement in the current frame and runs the hooks.
+func (e *Evaluator) beforeStatement(node ast.Statement, env *object.Environment) {
+	if len(e.frames) == 0 {
+		e.pushFrame("main", env)
+	}
+
+	e.frames[len(e.frames)-1].Statement = node
+
+	for _, hook := range e.Hooks {
+		hook(node, env)
+	}
+}
 func (e *Evaluator) evalClassStatement(node *ast.ClassStatement, env *object.Environment) object.Object {
 	name := node.Target.Value
+	// Constants are visible by name inside the class body.
+	classEnv := newEnclosedEnvironment(env)
 	klass := &object.Class{
+		Name:      name,
+		Methods:   make(map[string]*object.Function),
+		Statics:   make(map[string]*object.Function),
+		Constants: make(map[string]object.Object),
+		Defaults:  make(map[string]ast.Expression),
+		Env:       classEnv,
+	}
+
+	for _, constant := range node.Constants {
+		value := e.Eval(constant.Value, classEnv)
+		if value == nil {
+			return nil
+		}
+		if err := classEnv.DefineConst(constant.Target.Value, value); err != nil {
+			e.Error("%s", err)
+			return nil
+		}
+		klass.Constants[constant.Target.Value] = value
+	}
+
+	for _, field := range node.Fields {
+		target := field.Target.(*ast.Identifier).Value
+		if _, ok := klass.Defaults[target]; !ok {
+			klass.Fields = append(klass.Fields, target)
+		}
+		klass.Defaults[target] = field.Value
+	}
+
+	for _, method := range node.Statics {
+		fn := e.evalFunctionLiteral(method, classEnv)
+		fn.Name = name + "." + method.Target.Value
+
+		klass.Statics[method.Target.Value] = fn
+	}
+
+	for _, method := range node.Methods {
+		fn := e.evalFunctionLiteral(method, classEnv)
+		fn.Name = name + "." + method.Target.Value
+
+		klass.Methods[method.Target.Value] = fn
+	}
+
+	if !e.includeTraits(klass, node.Includes, env) {
+		return nil
+	}
+	if err := env.Define(name, klass); err != nil {
+		e.Error("%s", err)
+		return nil
+	}
+	return &object.Null{}
+}
+
+// includeTraits merges the methods of the included traits into klass.
+// Methods defined by the class win, two traits defining the same method conflict.
+func (e *Evaluator) includeTraits(klass *object.Class, includes []*ast.Identifier, env *object.Environment) bool {
+	from := map[string]*object.Trait{}
+
+	for _, include := range includes {
+		trait, ok := env.Get(include.Value).(*object.Trait)
+		if !ok {
+			e.Error("Class %s can't include %s, it is not a trait", klass.Name, include.Value)
+			return false
+		}
+
+		names := []string{}
+		for name := range trait.Methods {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if other, ok := from[name]; ok {
+				e.Error("Class %s gets method %s from both %s and %s", klass.Name, name, other.Name, trait.Name)
+				return false
+			}
+			from[name] = trait
+		}
+
+		klass.Traits = append(klass.Traits, trait)
+	}
+
+	for name, trait := range from {
+		if _, ok := klass.Methods[name]; !ok {
+			klass.Methods[name] = trait.Methods[name]
+		}
+	}
+
+	return true
+}
+func (e *Evaluator) evalTraitStatement(node *ast.TraitStatement, env *object.Environment) object.Object {
+	name := node.Target.Value
+	trait := &object.Trait{
 		Name:    name,
 		Methods: make(map[string]*object.Function),
 	}
 
 	for _, method := range node.Methods {
 		fn := e.evalFunctionLiteral(method, env)
+		fn.Name = name + "." + method.Target.Value
 
-		klass.Methods[method.Target.Value] = fn
+		trait.Methods[method.Target.Value] = fn
+	}
+	if err := env.Define(name, trait); err != nil {
+		e.Error("%s", err)
+		return nil
 	}
-	env.Set(name, klass)
 	return &object.Null{}
 }
 func (e *Evaluator) bindMethod(method *object.Function, instance *object.Instance) *object.Function {
-	method.Env.Set("this", instance)
+	return instance.Bind(method)
+}
 
-	return method
+// callMethod calls the method name of obj, ok is false if obj isn't an instance defining it.
+func (e *Evaluator) callMethod(obj object.Object, name string, args ...object.Object) (object.Object, bool) {
+	method, ok := object.Method(obj, name)
+	if !ok {
+		return nil, false
+	}
+	return e.Call(method, args), true
 }
 func (e *Evaluator) evalFieldExpression(node *ast.FieldExpression, env *object.Environment) object.Object {
 	target := e.Eval(node.Target, env)
 
+	// The target failed, its error has been reported.
+	if target == nil {
+		return nil
+	}
+
+	if node.Optional && target.Type() == object.NULL_OBJ {
+		return target
+	}
+
+	if module, ok := target.(*object.Module); ok {
+		return e.evalModuleMember(module, node.Field.Value)
+	}
+
+	if klass, ok := target.(*object.Class); ok {
+		return e.evalClassMember(klass, node.Field.Value)
+	}
+
 	if target.Type() != object.INSTANCE_OBJ {
 		e.Error("Can't access field on non-instance object %T", target)
 		return nil
@@ -75,13 +293,41 @@ func (e *Evaluator) evalFieldExpression(node *ast.FieldExpression, env *object.E
 		return val
 	}
 }
+func (e *Evaluator) evalClassMember(klass *object.Class, name string) object.Object {
+	val, ok := klass.Get(name)
+
+	if !ok {
+		e.Error("Class %s has no constant or static method %s", klass.Name, name)
+		return nil
+	}
+
+	return val
+}
+func (e *Evaluator) evalModuleMember(module *object.Module, name string) object.Object {
+	val, ok := module.Get(name)
+
+	if !ok {
+		e.Error("Module %s has no member %s", module.Name, name)
+		return nil
+	}
+
+	return val
+}
 
 func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
+	if !e.step() {
+		return &object.Null{}
+	}
+
 	switch node := node.(type) {
+	case *ast.ImportStatement:
+		return e.evalImportStatement(node, env)
 	case *ast.FieldExpression:
 		return e.evalFieldExpression(node, env)
 	case *ast.ClassStatement:
 		return e.evalClassStatement(node, env)
+	case *ast.TraitStatement:
+		return e.evalTraitStatement(node, env)
 	case *ast.WhileStatement:
 		return e.evalWhileStatement(node, env)
 	case *ast.TestStatement:
@@ -98,16 +344,36 @@ func (e *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
 		return e.evalIdentifier(node, env)
 	case *ast.AssignmentExpression:
 		return e.evalAssignmentExpression(node, env)
+	case *ast.CompoundAssignment:
+		return e.evalCompoundAssignment(node, env)
+	case *ast.SliceExpression:
+		return e.evalSliceExpression(node, env)
+	case *ast.PipeExpression:
+		return e.evalPipeExpression(node, env)
+	case *ast.LetStatement:
+		return e.evalLetStatement(node, env)
+	case *ast.ConstStatement:
+		return e.evalConstStatement(node, env)
 	case *ast.BlockStatement:
 		return e.evalBlockStatement(node, env)
 	case *ast.IfExpression:
 		return e.evalIfExpression(node, env)
+	case *ast.MatchExpression:
+		return e.evalMatchExpression(node, env)
 	case *ast.InfixExpression:
 		return e.evalInfixExpression(node, env)
 	case *ast.PrefixExpression:
 		return e.evalPrefixExpression(node, env)
+	case *ast.IndexExpression:
+		return e.evalIndexExpression(node, env)
+	case *ast.Array:
+		return e.evalArray(node, env)
+	case *ast.Map:
+		return e.evalMap(node, env)
 	case *ast.Boolean:
 		return evalBoolean(node)
+	case *ast.Null:
+		return &object.Null{}
 	case *ast.String:
 		return evalString(node)
 	case *ast.Integer:
@@ -146,6 +412,9 @@ func (e *Evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment
 func (e *Evaluator) assignField(node *ast.FieldExpression, value object.Object, env *object.Environment) object.Object {
 	target := e.Eval(node.Target, env)
 
+	return e.setField(target, node.Field.Value, value)
+}
+func (e *Evaluator) setField(target object.Object, name string, value object.Object) object.Object {
 	if target.Type() != object.INSTANCE_OBJ {
 		e.Error("Can't assign field on non-instance object %T", target)
 		return nil
@@ -158,22 +427,69 @@ func (e *Evaluator) assignField(node *ast.FieldExpression, value object.Object,
 		return nil
 	}
 
-	instance.Set(node.Field.Value, value)
+	if err := instance.Set(name, value); err != nil {
+		e.Error("%s", err)
+		return nil
+	}
+	return value
+}
+func (e *Evaluator) assignIndex(node *ast.IndexExpression, value object.Object, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	index := e.Eval(node.Index, env)
+
+	return e.setIndex(left, index, value)
+}
+func (e *Evaluator) setIndex(left object.Object, index object.Object, value object.Object) object.Object {
+	var err error
+
+	switch left := left.(type) {
+	case *object.Array:
+		i, ok := e.position(index, len(left.Elements))
+		if !ok {
+			return nil
+		}
+		err = left.Set(i, value)
+	case *object.Map:
+		err = left.Set(index, value)
+	default:
+		e.Error("Can't assign index on %T", left)
+		return nil
+	}
+
+	if err != nil {
+		e.Error("%s", err)
+		return nil
+	}
 	return value
 }
 func (e *Evaluator) evalAssignmentExpression(node *ast.AssignmentExpression, env *object.Environment) object.Object {
 	value := e.Eval(node.Value, env)
+	if value == nil {
+		return nil
+	}
 
-	switch target := node.Target.(type) {
-	case *ast.Identifier:
-		env.Set(target.Value, value)
-		return value
-	case *ast.FieldExpression:
-		return e.assignField(target, value, env)
-	default:
-		e.Error("Can't assign to expression %T", node.Target)
+	if !e.destructure(node.Target, value, env, false) {
+		return nil
+	}
+	return value
+}
+func (e *Evaluator) evalLetStatement(node *ast.LetStatement, env *object.Environment) object.Object {
+	value := e.Eval(node.Value, env)
+
+	if err := env.Define(node.Target.Value, value); err != nil {
+		e.Error("%s", err)
+		return nil
+	}
+	return value
+}
+func (e *Evaluator) evalConstStatement(node *ast.ConstStatement, env *object.Environment) object.Object {
+	value := e.Eval(node.Value, env)
+
+	if err := env.DefineConst(node.Target.Value, value); err != nil {
+		e.Error("%s", err)
 		return nil
 	}
+	return value
 }
 func (e *Evaluator) evalBlockStatement(node *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
@@ -181,8 +497,9 @@ func (e *Evaluator) evalBlockStatement(node *ast.BlockStatement, env *object.Env
 	result = &object.Null{}
 
 	for _, statement := range node.Statements {
+		e.beforeStatement(statement, env)
 		result = e.Eval(statement, env)
-		if result.Type() == object.RETURN_OBJ {
+		if result != nil && result.Type() == object.RETURN_OBJ {
 			return result
 		}
 	}
@@ -206,6 +523,73 @@ func (e *Evaluator) evalIfExpression(node *ast.IfExpression, env *object.Environ
 	}
 	return &object.Null{}
 }
+func (e *Evaluator) evalArray(node *ast.Array, env *object.Environment) object.Object {
+	elements := []object.Object{}
+
+	for _, element := range node.Elements {
+		value := e.Eval(element, env)
+		if value == nil {
+			return nil
+		}
+		elements = append(elements, value)
+	}
+
+	return &object.Array{Elements: elements}
+}
+func (e *Evaluator) evalMap(node *ast.Map, env *object.Environment) object.Object {
+	m := object.NewMap()
+
+	for i, key := range node.Keys {
+		k := e.Eval(key, env)
+		if k == nil {
+			return nil
+		}
+
+		v := e.Eval(node.Values[i], env)
+		if v == nil {
+			return nil
+		}
+
+		if err := m.Set(k, v); err != nil {
+			e.Error("Can't create map: %s", err)
+			return nil
+		}
+	}
+
+	return m
+}
+func (e *Evaluator) evalIndexExpression(node *ast.IndexExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	index := e.Eval(node.Index, env)
+
+	return e.index(left, index)
+}
+
+// index looks up a value, sequences are indexed from 1.
+func (e *Evaluator) index(left object.Object, index object.Object) object.Object {
+	if instance, ok := left.(*object.Instance); ok {
+		if value, ok := e.callMethod(instance, "index", index); ok {
+			return value
+		}
+		e.Error("Can't index instance of %s, it has no index method", instance.Class.Name)
+		return nil
+	}
+
+	value, err := object.Index(left, index)
+	if err != nil {
+		e.Error("%s", err)
+		return nil
+	}
+	return value
+}
+func (e *Evaluator) position(index object.Object, length int) (int, bool) {
+	i, err := object.Position(index, length)
+	if err != nil {
+		e.Error("%s", err)
+		return 0, false
+	}
+	return i, true
+}
 func evalString(node *ast.String) object.Object {
 	return &object.String{Value: node.Value}
 }
@@ -221,6 +605,7 @@ func (e *Evaluator) evalProgram(node *ast.Program, env *object.Environment) obje
 	var result object.Object
 
 	for _, statement := range node.Statements {
+		e.beforeStatement(statement, env)
 		result = e.Eval(statement, env)
 	}
 
@@ -235,16 +620,37 @@ func (e *Evaluator) evalInfixExpression(node *ast.InfixExpression, env *object.E
 		return e.evalInfixComparison(node, env)
 	case token.OR, token.AND:
 		return e.evalInfixLogical(node, env)
+	case token.COALESCE:
+		return e.evalCoalesce(node, env)
+	case token.RANGE, token.ELLIPSIS:
+		return e.evalRange(node, env)
 	default:
 		e.Error("Unknown infix operator: %q", node.Operator)
 		return nil
 	}
 }
-func isEqual(left object.Object, right object.Object) bool {
-	if left.Type() == right.Type() && left.String() == right.String() {
-		return true
+
+// evalCoalesce only evaluates the right side when the left one is null.
+func (e *Evaluator) evalCoalesce(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+
+	if left != nil && left.Type() != object.NULL_OBJ {
+		return left
 	}
-	return false
+
+	return e.Eval(node.Right, env)
+}
+
+// isEqual calls the eq method of instances which define it.
+func (e *Evaluator) isEqual(left object.Object, right object.Object) bool {
+	if value, ok := e.callMethod(left, "eq", right); ok {
+		return isTruthy(value)
+	}
+	equal, err := object.Equal(left, right, e)
+	if err != nil {
+		e.Error("%s", err)
+	}
+	return equal
 }
 func (e *Evaluator) evalInfixLogical(node *ast.InfixExpression, env *object.Environment) object.Object {
 	left := e.Eval(node.Left, env)
@@ -266,34 +672,36 @@ func (e *Evaluator) evalInfixComparison(node *ast.InfixExpression, env *object.E
 
 	switch node.Operator {
 	case token.EQ:
-		return &object.Boolean{Value: isEqual(left, right)}
+		return &object.Boolean{Value: e.isEqual(left, right)}
 	case token.NOT_EQ:
-		return &object.Boolean{Value: !isEqual(left, right)}
+		return &object.Boolean{Value: !e.isEqual(left, right)}
 	case token.GT:
-		return e.evalGreaterThan(left, right)
+		return e.evalLessThan(right, left)
 	case token.LT:
 		return e.evalLessThan(left, right)
 	case token.GTE:
-		return &object.Boolean{Value: !isTruthy(e.evalLessThan(left, right))}
+		return e.negate(e.evalLessThan(left, right))
 	case token.LTE:
-		return &object.Boolean{Value: !isTruthy(e.evalGreaterThan(left, right))}
+		return e.negate(e.evalLessThan(right, left))
 	default:
 		e.Error("Unknown infix comparison operator: %s", node.Operator)
 		return nil
 	}
 }
-func (e *Evaluator) evalGreaterThan(left, right object.Object) object.Object {
-	leftInt := toInteger(left)
-	rightInt := toInteger(right)
-
-	if leftInt == nil || rightInt == nil {
-		e.Error("Can't compare expressions %T and %T", left, right)
+func (e *Evaluator) negate(value object.Object) object.Object {
+	if value == nil {
 		return nil
 	}
-	return &object.Boolean{Value: leftInt.Value > rightInt.Value}
-
+	return &object.Boolean{Value: !isTruthy(value)}
 }
+
+// evalLessThan compares integers, or calls the lt method of an instance.
+// The other comparisons swap or negate it, so `a > b` calls `b.lt(a)`.
 func (e *Evaluator) evalLessThan(left, right object.Object) object.Object {
+	if value, ok := e.callMethod(left, "lt", right); ok {
+		return &object.Boolean{Value: isTruthy(value)}
+	}
+
 	leftInt := toInteger(left)
 	rightInt := toInteger(right)
 
@@ -303,19 +711,41 @@ func (e *Evaluator) evalLessThan(left, right object.Object) object.Object {
 	}
 	return &object.Boolean{Value: leftInt.Value < rightInt.Value}
 }
+
+// operatorMethods are the methods instances define to overload the arithmetic operators.
+var operatorMethods = map[token.TokenType]string{
+	token.PLUS:     "add",
+	token.MINUS:    "sub",
+	token.MULTIPLY: "mul",
+	token.DIVIDE:   "div",
+	token.MOD:      "mod",
+}
+
 func (e *Evaluator) evalInfixArithmetic(node *ast.InfixExpression, env *object.Environment) object.Object {
-	left := toInteger(e.Eval(node.Left, env))
+	leftValue := e.Eval(node.Left, env)
+	rightValue := e.Eval(node.Right, env)
+
+	return e.arithmetic(node.Operator, leftValue, rightValue)
+}
+
+// arithmetic applies operator, calling the operator method of instances which define it.
+func (e *Evaluator) arithmetic(operator token.TokenType, leftValue object.Object, rightValue object.Object) object.Object {
+	if value, ok := e.callMethod(leftValue, operatorMethods[operator], rightValue); ok {
+		return value
+	}
+
+	left := toInteger(leftValue)
 	if left == nil {
-		e.Error("Can't perform infix operation on left expression %T", left)
+		e.Error("Can't perform infix operation on left expression %T", leftValue)
 		return nil
 	}
-	right := toInteger(e.Eval(node.Right, env))
+	right := toInteger(rightValue)
 	if right == nil {
-		e.Error("Can't perform infix operation on right expression %T", node.Right)
+		e.Error("Can't perform infix operation on right expression %T", rightValue)
 		return nil
 	}
 
-	switch node.Operator {
+	switch operator {
 	case token.PLUS:
 		return &object.Integer{Value: left.Value + right.Value}
 	case token.MINUS:
@@ -327,7 +757,7 @@ func (e *Evaluator) evalInfixArithmetic(node *ast.InfixExpression, env *object.E
 	case token.MOD:
 		return &object.Integer{Value: left.Value % right.Value}
 	default:
-		e.Error("Unknown arithmetic infix operator: %s", node.Operator)
+		e.Error("Unknown arithmetic infix operator: %s", operator)
 		return nil
 	}
 }
@@ -386,11 +816,21 @@ func getArgumentNames(args []*ast.Identifier) []string {
 
 	return names
 }
+func getRestName(rest *ast.Identifier) string {
+	if rest == nil {
+		return ""
+	}
+	return rest.Value
+}
 func (e *Evaluator) evalFunctionLiteral(node *ast.FunctionStatement, env *object.Environment) *object.Function {
 	args := getArgumentNames(node.Arguments)
 
 	fn := &object.Function{
+		Name:      node.Target.Value,
 		Arguments: args,
+		Defaults:  node.Defaults,
+		Rest:      getRestName(node.Rest),
+		Patterns:  node.Patterns,
 		Body:      node.Body,
 		Env:       env,
 	}
@@ -403,7 +843,10 @@ func (e *Evaluator) evalFunctionStatement(node *ast.FunctionStatement, env *obje
 
 	name := node.Target.Value
 
-	env.Set(name, fn)
+	if err := env.Define(name, fn); err != nil {
+		e.Error("%s", err)
+		return nil
+	}
 
 	return &object.Null{}
 }
@@ -412,6 +855,9 @@ func (e *Evaluator) evalLambdaExpression(node *ast.Lambda, env *object.Environme
 
 	fn := &object.Function{
 		Arguments: args,
+		Defaults:  node.Defaults,
+		Rest:      getRestName(node.Rest),
+		Patterns:  node.Patterns,
 		Body:      node.Body,
 		Env:       env,
 	}
@@ -419,68 +865,191 @@ func (e *Evaluator) evalLambdaExpression(node *ast.Lambda, env *object.Environme
 	return fn
 }
 func newEnclosedEnvironment(outer *object.Environment) *object.Environment {
-	env := object.NewEnvironment()
-
-	env.Outer = outer
-
-	return env
+	return object.NewEnclosedEnvironment(outer)
 }
-func (e *Evaluator) evalArgs(args []ast.Expression, env *object.Environment) []object.Object {
-	var evaluated []object.Object
-
-	for _, arg := range args {
-		evaluated = append(evaluated, e.Eval(arg, env))
-	}
-
-	return evaluated
-}
-func (e *Evaluator) evalClassCall(klass *object.Class, args []object.Object) object.Object {
+func (e *Evaluator) evalClassCall(klass *object.Class, args []object.Object, keywords []*keywordArgument) object.Object {
 	instance := &object.Instance{
 		Class:   klass,
 		Map:     make(map[string]object.Object),
 		Methods: klass.Methods,
 	}
 
+	for _, field := range klass.Fields {
+		value := e.Eval(klass.Defaults[field], klass.Env)
+		if value == nil {
+			return nil
+		}
+		instance.Map[field] = value
+	}
+
 	constructor, ok := klass.Methods["init"]
 
 	// If the class has an init method, call it
 	if ok {
+		if !e.enter() {
+			return &object.Null{}
+		}
+		defer e.leave()
+
+		constructor = e.bindMethod(constructor, instance)
+
 		newEnv := newEnclosedEnvironment(constructor.Env)
-		e.applyArguments(constructor.Arguments, args, newEnv)
-		e.bindMethod(constructor, instance)
-		e.Eval(constructor.Body, newEnv)
+		e.pushFrame(constructor.Name, newEnv)
+		defer e.popFrame()
+
+		if !e.bindArguments(constructor, args, keywords, newEnv) {
+			return nil
+		}
+		// A failed init leaves the instance half built, it isn't returned.
+		if e.Eval(constructor.Body, newEnv) == nil {
+			return nil
+		}
+	} else if len(keywords) > 0 {
+		e.Error("%s() got an unexpected keyword argument %s", klass.Name, keywords[0].name)
+		return nil
 	}
 
 	return instance
 }
-func (e *Evaluator) evalCallExpression(node *ast.CallExpression, env *object.Environment) object.Object {
+
+// evalCallExpression calls the function, the piped values come before the arguments.
+func (e *Evaluator) evalCallExpression(node *ast.CallExpression, env *object.Environment, piped ...object.Object) object.Object {
 	ex := e.Eval(node.Function, env)
-	args := e.evalArgs(node.Arguments, env)
 
+	// Calling a method through `?.` on null is null too.
+	if field, ok := node.Function.(*ast.FieldExpression); ok && field.Optional && ex != nil && ex.Type() == object.NULL_OBJ {
+		return ex
+	}
+
+	args, keywords := e.evalCallArgs(node.Arguments, env)
+
+	return e.call(ex, append(piped, args...), keywords)
+}
+
+// evalPipeExpression calls the right side with the left one as the first argument.
+func (e *Evaluator) evalPipeExpression(node *ast.PipeExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	if left == nil {
+		return nil
+	}
+
+	if call, ok := node.Right.(*ast.CallExpression); ok {
+		return e.evalCallExpression(call, env, left)
+	}
+
+	fn := e.Eval(node.Right, env)
+	if fn == nil {
+		return nil
+	}
+
+	return e.call(fn, []object.Object{left}, nil)
+}
+
+// Call calls a function, builtin, class or callable instance with already evaluated arguments.
+func (e *Evaluator) Call(ex object.Object, args []object.Object) object.Object {
+	return e.call(ex, args, nil)
+}
+func (e *Evaluator) call(ex object.Object, args []object.Object, keywords []*keywordArgument) object.Object {
 	switch ex := ex.(type) {
 	case *object.Function:
-		return e.evalFunctionCall(ex, args)
+		return e.evalFunctionCall(ex, args, keywords)
 	case *object.Builtin:
+		if len(keywords) > 0 {
+			e.Error("%s() doesn't take keyword arguments", ex.Name)
+			return nil
+		}
 		return e.evalBuiltinCall(ex, args)
 	case *object.Class:
-		return e.evalClassCall(ex, args)
+		return e.evalClassCall(ex, args, keywords)
+	case *object.Instance:
+		method, ok := object.Method(ex, "call")
+		if !ok {
+			e.Error("Can't call instance of %s, it has no call method", ex.Class.Name)
+			return nil
+		}
+		return e.evalFunctionCall(method, args, keywords)
 	default:
 		e.Error("Can't call expression %T", ex)
 		return nil
 	}
 }
+
+// Invoke calls fn on behalf of a builtin, errors during the call are reported to the handler as well.
+func (e *Evaluator) Invoke(fn object.Object, args ...object.Object) (object.Object, error) {
+	result := e.Call(fn, args)
+
+	// The cause has already gone to the ErrorHandler.
+	if e.Budget != nil && e.Budget.Err() != nil {
+		return nil, &reportedError{err: e.Budget.Err()}
+	}
+
+	if result == nil {
+		return nil, &reportedError{err: fmt.Errorf("calling %s failed", fn.Type())}
+	}
+
+	return result, nil
+}
+
+// Context returns the context of the current run, builtins waiting on I/O stop when it's done.
+func (e *Evaluator) Context() context.Context {
+	if e.Budget == nil {
+		return context.Background()
+	}
+	return e.Budget.Context()
+}
+
+// Step charges a unit of work done by a builtin to the budget.
+// A limit exceeded earlier has already been reported.
+func (e *Evaluator) Step() error {
+	if e.Budget == nil {
+		return nil
+	}
+
+	if err := e.Budget.Err(); err != nil {
+		return &reportedError{err: err}
+	}
+
+	return e.Budget.Step()
+}
+
+// reportedError is returned by Invoke for failures the ErrorHandler has already seen.
+type reportedError struct {
+	err error
+}
+
+func (r *reportedError) Error() string {
+	return r.err.Error()
+}
+
+func (r *reportedError) Unwrap() error {
+	return r.err
+}
+
 func (e *Evaluator) evalBuiltinCall(fn *object.Builtin, args []object.Object) object.Object {
-	value, err := fn.Fn(args...)
+	value, err := fn.Call(e, args...)
 	if err != nil {
-		e.Error("Error calling builtin function %s: %s", fn.Name, err)
+		var reported *reportedError
+		if !errors.As(err, &reported) {
+			e.Error("Error calling builtin function %s: %w", fn.Name, err)
+		}
 		return nil
 	}
 	return value
 }
-func (e *Evaluator) evalFunctionCall(fn *object.Function, args []object.Object) object.Object {
+func (e *Evaluator) evalFunctionCall(fn *object.Function, args []object.Object, keywords []*keywordArgument) object.Object {
+	if !e.enter() {
+		return &object.Null{}
+	}
+	defer e.leave()
+
 	newEnv := newEnclosedEnvironment(fn.Env)
 
-	e.applyArguments(fn.Arguments, args, newEnv)
+	e.pushFrame(functionName(fn), newEnv)
+	defer e.popFrame()
+
+	if !e.bindArguments(fn, args, keywords, newEnv) {
+		return nil
+	}
 
 	evaluated := e.Eval(fn.Body, newEnv)
 
@@ -492,17 +1061,6 @@ func unwrapReturnValue(obj object.Object) object.Object {
 	}
 	return obj
 }
-func (e *Evaluator) applyArguments(params []string, args []object.Object, env *object.Environment) {
-
-	if len(params) != len(args) {
-		e.Error("Expected %d arguments, got %d", len(params), len(args))
-		return
-	}
-
-	for i, arg := range args {
-		env.Set(params[i], arg)
-	}
-}
 func toFunction(node object.Object) *object.Function {
 	fn, ok := node.(*object.Function)
 	if !ok {
@@ -1,9 +1,17 @@
 package eval
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/budget"
 	"github.com/pspiagicw/fener/lexer"
 	"github.com/pspiagicw/fener/object"
 	"github.com/pspiagicw/fener/parser"
@@ -85,6 +93,297 @@ func TestFieldExpressoin(t *testing.T) {
 	runTableTests(t, table)
 }
 
+func TestArray(t *testing.T) {
+	table := []testCase{
+		{`[1, 2, 3][1]`, 1},
+		{`a = [1, 2 + 3, "three"] a[2]`, 5},
+		{`a = [1, 2, "three"] a[len(a)]`, "three"},
+		{`len([])`, 0},
+		{`"fener"[2]`, "e"},
+		{`fn first(xs) return xs[1] end first([7, 8])`, 7},
+	}
+	runTableTests(t, table)
+}
+
+func TestMap(t *testing.T) {
+	table := []testCase{
+		{`{"name" = "fener"}["name"]`, "fener"},
+		{`m = {1 = "one", true = "yes"} m[true]`, "yes"},
+		{`m = {"a" = 1, "b" = 2, "a" = 3} len(m)`, 2},
+		{`m = {"a" = 1} m["b"]`, nil},
+	}
+	runTableTests(t, table)
+}
+
+func TestCollectionErrors(t *testing.T) {
+	inputs := []string{
+		"[nope, 1]",
+		"[1, [2, nope]]",
+		"{nope = 1}",
+		`{"a" = nope}`,
+		"len(undefined)",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			program, parseErrors := parse(input)
+
+			if len(parseErrors) > 0 {
+				t.Fatalf("Parsing failed: %v", parseErrors)
+			}
+
+			// Like the REPL, keep evaluating after an error.
+			var errs []error
+			e := New(func(err error) { errs = append(errs, err) })
+
+			result := e.Eval(program, object.NewEnvironment())
+
+			if len(errs) == 0 {
+				t.Fatalf("Expected an error")
+			}
+
+			if result != nil {
+				t.Fatalf("Expected no result, got %v", result)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	table := []testCase{
+		{`import "math" math.abs(-5)`, 5},
+		{`import "math" as m m.gcd(12, 18)`, 6},
+		{`import "func" f = func.compose(fn(x) x + 1 end, fn(x) x * 2 end) f(5)`, 11},
+	}
+	runTableTests(t, table)
+
+	// The builtins of a module's environment aren't its members.
+	checkError(t, `import "math" math.print(1)`, "Module math has no member print")
+}
+
+func TestImportFile(t *testing.T) {
+	dir := t.TempDir()
+
+	writeModule(t, dir, "shapes.fn", `fn area(w, h) w * h end`)
+	writeModule(t, dir, "lib/counter.fn", `import "../shapes" total = shapes.area(2, 3)`)
+	writeModule(t, dir, "a.fn", `import "b"`)
+	writeModule(t, dir, "b.fn", `import "a"`)
+
+	checkFile(t, filepath.Join(dir, "main.fn"), `import "lib/counter" counter.total`, 6)
+
+	var err error
+	e := New(func(e error) {
+		if err == nil {
+			err = e
+		}
+	})
+	e.File = filepath.Join(dir, "main.fn")
+
+	program, _ := parse(`import "a"`)
+	e.Eval(program, object.NewEnvironment())
+
+	if err == nil || !strings.Contains(err.Error(), "import cycle detected: a -> b -> a") {
+		t.Fatalf("Expected import cycle error, got %v", err)
+	}
+}
+
+func TestImportSandbox(t *testing.T) {
+	dir := t.TempDir()
+
+	writeModule(t, dir, "shapes.fn", `fn area(w, h) w * h end`)
+	writeModule(t, dir, "lib/counter.fn", `import "../shapes" total = shapes.area(2, 3)`)
+
+	table := []struct {
+		input    string
+		expected string
+	}{
+		{`import "math" math.abs(-2)`, ""},
+		{`import "shapes"`, `permission denied: import needs the "fs-read" capability`},
+		{`import "./shapes"`, `permission denied: import needs the "fs-read" capability`},
+		{`import "lib/counter"`, `permission denied: import needs the "fs-read" capability`},
+		{fmt.Sprintf(`import %q`, filepath.Join(dir, "shapes")), `permission denied: import needs the "fs-read" capability`},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.input, func(t *testing.T) {
+			program, _ := parse(tt.input)
+
+			var errs []error
+			e := New(func(err error) { errs = append(errs, err) })
+			e.File = filepath.Join(dir, "main.fn")
+			e.Eval(program, object.NewSandboxedEnvironment(object.NewCapabilities()))
+
+			if tt.expected == "" {
+				if len(errs) != 0 {
+					t.Fatalf("Unexpected errors: %v", errs)
+				}
+				return
+			}
+
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.expected) {
+				t.Fatalf("Expected %q, got %v", tt.expected, errs)
+			}
+		})
+	}
+}
+
+func TestImportState(t *testing.T) {
+	dir := t.TempDir()
+
+	writeModule(t, dir, "broken.fn", `x = 1 + "a"`)
+	writeModule(t, dir, "checked.fn", "ran = false\ntest \"module\"\n ran = true\nend\n")
+
+	var errs []error
+	e := New(func(err error) { errs = append(errs, err) })
+	e.File = filepath.Join(dir, "main.fn")
+
+	// A failed module isn't cached, importing it again evaluates it again.
+	program, _ := parse(`import "broken" import "broken"`)
+	e.Eval(program, object.NewEnvironment())
+
+	failures := 0
+	for _, err := range errs {
+		if strings.Contains(err.Error(), `evaluating module "broken" failed`) {
+			failures++
+		}
+	}
+
+	if failures != 2 {
+		t.Fatalf("Expected the module to fail twice, got %v", errs)
+	}
+
+	// Modules run with the hooks and test mode of the importer.
+	lines := []int{}
+	e.Hooks = append(e.Hooks, func(node ast.Statement, env *object.Environment) {
+		if stmt, ok := node.(*ast.ExpressionStatement); ok {
+			lines = append(lines, stmt.Token.Line)
+		}
+	})
+	e.Test = true
+
+	program, _ = parse(`import "checked" checked.ran`)
+	checkValue(t, e.Eval(program, object.NewEnvironment()), true)
+
+	if len(lines) < 3 {
+		t.Errorf("Expected the hooks to see the module's statements, got lines %v", lines)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	table := []struct {
+		input  string
+		limits budget.Limits
+		limit  string
+	}{
+		{`while true then end`, budget.Limits{MaxSteps: 1000}, budget.STEPS},
+		{`fn loop(n) loop(n + 1) end loop(1)`, budget.Limits{MaxDepth: 50}, budget.DEPTH},
+		{`map(1..4000000000, fn(x) x end)`, budget.Limits{MaxSteps: 1000}, budget.STEPS},
+		{`filter(1..4000000000, fn(x) false end)`, budget.Limits{MaxSteps: 1000}, budget.STEPS},
+		{`reduce(1..4000000000, fn(a, b) a end)`, budget.Limits{MaxSteps: 1000}, budget.STEPS},
+		{`sort(1..4000000000)`, budget.Limits{MaxSteps: 1000}, budget.STEPS},
+		{`sort_by(1..4000000000, str)`, budget.Limits{MaxSteps: 1000}, budget.STEPS},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.input, func(t *testing.T) {
+			err := evalWithBudget(t, tt.input, budget.New(context.Background(), tt.limits))
+
+			var limitErr *budget.LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Fatalf("Expected %s limit error, got %v", tt.limit, err)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	for _, input := range []string{`while true then end`, `sort(1..4000000000)`} {
+		err := evalWithBudget(t, input, budget.New(ctx, budget.Limits{}))
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected deadline error for %s, got %v", input, err)
+		}
+	}
+}
+
+func TestInvokeLimits(t *testing.T) {
+	program, parseErrors := parse(`double = fn(x) x * 2 end while true then end`)
+
+	if len(parseErrors) > 0 {
+		t.Fatalf("Parsing failed: %v", parseErrors)
+	}
+
+	var errs []error
+	e := New(func(err error) {
+		errs = append(errs, err)
+	})
+	e.Budget = budget.New(context.Background(), budget.Limits{MaxSteps: 100})
+
+	env := object.NewEnvironment()
+	e.Eval(program, env)
+
+	// The limit is only reported once, but callers of Invoke still see it.
+	_, err := e.Invoke(env.Get("double"), &object.Integer{Value: 1})
+
+	var limitErr *budget.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != budget.STEPS {
+		t.Fatalf("Expected step limit error, got %v", err)
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected a single error, got %v", errs)
+	}
+}
+
+func evalWithBudget(t *testing.T, input string, b *budget.Budget) error {
+	t.Helper()
+
+	program, parseErrors := parse(input)
+
+	if len(parseErrors) > 0 {
+		t.Fatalf("Parsing failed: %v", parseErrors)
+	}
+
+	var errs []error
+	e := New(func(err error) {
+		errs = append(errs, err)
+	})
+	e.Budget = b
+
+	e.Eval(program, object.NewEnvironment())
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected a single error, got %v", errs)
+	}
+
+	return errs[0]
+}
+
+func TestHooks(t *testing.T) {
+	program, parseErrors := parse("fn double(n)\n return n * 2\nend\nx = double(4)\n")
+
+	if len(parseErrors) > 0 {
+		t.Fatalf("Parsing failed: %v", parseErrors)
+	}
+
+	e := New(func(err error) { t.Fatalf("Unexpected error: %v", err) })
+
+	visited := []string{}
+	e.Hooks = append(e.Hooks, func(node ast.Statement, env *object.Environment) {
+		frames := e.Frames()
+		visited = append(visited, fmt.Sprintf("%s:%s", frames[len(frames)-1].Name, node.Name()))
+	})
+
+	e.Eval(program, object.NewEnvironment())
+
+	expected := []string{"main:FunctionStatement", "main:ExpressionStatement", "double:ReturnStatement"}
+
+	if strings.Join(visited, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected hooks for %v, got %v", expected, visited)
+	}
+}
+
 func TestTest(t *testing.T) {
 	table := []testCase{
 		{
@@ -118,10 +417,528 @@ func TestClosure(t *testing.T) {
              `,
 			1,
 		},
+		{
+			`fn counter()
+                count = 0
+                return fn() count = count + 1 end
+             end
+             c = counter()
+             c()
+             c()
+             `,
+			2,
+		},
 	}
 	runTableTests(t, table)
 }
 
+func TestScoping(t *testing.T) {
+	table := []testCase{
+		{"total = 1 fn add(n) total = total + n end add(2) total", 3},
+		{"total = 1 fn add(n) let total = n end add(2) total", 1},
+		{"fn f() local = 5 end f() x = 1 x", 1},
+		{"let a = 1 let a = 2 a", 2},
+		{"x = 1 fn f() let x = 10 g = fn() x = x + 1 end g() x end f()", 11},
+		{"x = 1 fn f() let x = 10 g = fn() x = x + 1 end g() x end f() x", 1},
+		{"fn f(n) len = n len end f(3)", 3},
+		{"fn f(n) len = n end f(3) len([1, 2])", 2},
+	}
+
+	runTableTests(t, table)
+}
+
+func TestConst(t *testing.T) {
+	table := []testCase{
+		{"const LIMIT = 10 LIMIT", 10},
+		{"const LIMIT = 10 fn f() let LIMIT = 3 LIMIT end f()", 3},
+		{"const LIMIT = 10 fn f() LIMIT + 1 end f()", 11},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"const LIMIT = 10 LIMIT = 5", "can't assign to constant LIMIT"},
+		{"const LIMIT = 10 fn f() LIMIT = 5 end f()", "can't assign to constant LIMIT"},
+		{"const LIMIT = 10 let LIMIT = 5", "can't assign to constant LIMIT"},
+		{"const LIMIT = 10 const LIMIT = 5", "can't assign to constant LIMIT"},
+		{"const f = 1 fn f() end", "can't assign to constant f"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	table := []testCase{
+		{"xs = [1, 2, 3] xs[2] = 20 xs[2]", 20},
+		{`m = { "a" = 1 } m["b"] = 2 m["b"]`, 2},
+		{"xs = freeze([1, 2]) xs[1]", 1},
+		{"xs = freeze([1, 2]) ys = [xs] ys[1] = 3 ys[1]", 3},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"xs = freeze([1, 2]) xs[1] = 3", "can't modify a frozen array"},
+		{`m = freeze({ "a" = 1 }) m["a"] = 2`, "can't modify a frozen map"},
+		{`m = freeze({ "a" = [1] }) m["a"][1] = 2`, "can't modify a frozen array"},
+		{"class P fn init() this.x = 1 end end p = freeze(P()) p.x = 2", "can't modify a frozen instance"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+const pointClass = `
+class Point
+    const DIMENSIONS = 2
+    label = "point"
+    fn init(x, y)
+        this.x = x
+        this.y = y
+    end
+    fn dimensions()
+        DIMENSIONS
+    end
+    static fn origin()
+        Point(0, 0)
+    end
+end
+a = Point(1, 2)
+`
+
+func TestClassMembers(t *testing.T) {
+	table := []testCase{
+		{pointClass + "Point.DIMENSIONS", 2},
+		{pointClass + "a.dimensions()", 2},
+		{pointClass + "o = Point.origin() o.x", 0},
+		{pointClass + "a.label", "point"},
+		{pointClass + `class Label label = "a" fn init() this.label = "b" end end l = Label() l.label`, "b"},
+		{`class Bag items = {} end a = Bag() b = Bag() a.items["k"] = 1 len(b.items)`, 0},
+		{pointClass + "str(fields(a))", "[label, x, y]"},
+		{pointClass + "str(methods(Point))", "[dimensions, init]"},
+		{pointClass + "class_of(a) == Point", true},
+		{pointClass + "is_instance(a, Point)", true},
+		{pointClass + "class Other end is_instance(a, Other)", false},
+		{pointClass + "is_instance(1, Point)", false},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{pointClass + "Point.x", "Class Point has no constant or static method x"},
+		{pointClass + "a.origin()", "Field not found: origin"},
+		{"class P const X = 1 fn init() X = 2 end end P()", "can't assign to constant X"},
+		{pointClass + "class_of(1)", "Error calling builtin function class_of: argument to CLASS_OF must be an instance, got INTEGER"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+const traitClasses = `
+trait Describe
+    fn describe()
+        this.name()
+    end
+    fn name()
+        "thing"
+    end
+end
+trait Compare
+    fn compare(other)
+        this.size() - other.size()
+    end
+end
+class Box
+    include Describe
+    include Compare
+    fn init(size)
+        this.box = size
+    end
+    fn size()
+        this.box
+    end
+    fn name()
+        "box"
+    end
+end
+class Plain
+end
+a = Box(3)
+b = Box(1)
+`
+
+func TestTraits(t *testing.T) {
+	table := []testCase{
+		{traitClasses + "a.describe()", "box"},
+		{traitClasses + "a.compare(b)", 2},
+		{traitClasses + "implements(a, Compare)", true},
+		{traitClasses + "implements(Box, Describe)", true},
+		{traitClasses + "implements(Plain(), Describe)", false},
+		{traitClasses + "implements(1, Describe)", false},
+		{traitClasses + "str(methods(Box))", "[compare, describe, init, name, size]"},
+		{traitClasses + "str(Describe)", "Describe"},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"trait A fn f() 1 end end trait B fn f() 2 end end class C include A include B end", "Class C gets method f from both A and B"},
+		{"x = 1 class C include x end", "Class C can't include x, it is not a trait"},
+		{traitClasses + "implements(a, Box)", "Error calling builtin function implements: second argument to IMPLEMENTS must be a trait, got CLASS"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestNull(t *testing.T) {
+	people := `
+class Person
+    fn init(name, friend)
+        this.name = name
+        this.friend = friend
+    end
+    fn greet()
+        "hi"
+    end
+end
+a = Person("a", null)
+b = Person("b", a)
+`
+
+	table := []testCase{
+		{"null", nil},
+		{"x = null x == null", true},
+		{"null == 0", false},
+		{`"null" == null`, false},
+		{"null != null", false},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] == [1, 3]", false},
+		{`{ "a" = 1, "b" = 2 } == { "b" = 2, "a" = 1 }`, true},
+		{"xs = [1] xs[1] = xs ys = [1] ys[1] = ys xs == ys", true},
+		{people + "b == Person(\"b\", Person(\"a\", null))", true},
+		{people + "a == b", false},
+		{people + "b?.friend?.name", "a"},
+		{people + "a?.friend?.name", nil},
+		{people + "a.friend?.greet()", nil},
+		{people + "b.friend?.greet()", "hi"},
+		{people + `a.friend?.name ?? "nobody"`, "nobody"},
+		{"0 ?? 1", 0},
+		{"false ?? 1", false},
+		{"null ?? null ?? 3", 3},
+		{"x = 1 null ?? 2 ?? (x = 5) x", 1},
+	}
+
+	runTableTests(t, table)
+
+	checkError(t, people+"a.friend.name", "Can't access field on non-instance object *object.Null")
+}
+
+func TestMatch(t *testing.T) {
+	shapes := `
+class Point
+    fn init(x, y)
+        this.x = x
+        this.y = y
+    end
+end
+fn describe(value)
+    match value
+    case 0 then "zero"
+    case -1 then "minus one"
+    case "hi" then "greeting"
+    case null then "nothing"
+    case [] then "empty"
+    case [x] then "one"
+    case [x, [y, _]] then "nested"
+    case { "name" = name } then name
+    case Point(0, 0) then "origin"
+    case Point(x, y) if x == y then "diagonal"
+    case Point(y: y) then y
+    case n if n == 101 then "big"
+    case _ then "other"
+    end
+end
+`
+
+	table := []testCase{
+		{shapes + "describe(0)", "zero"},
+		{shapes + "describe(-1)", "minus one"},
+		{shapes + `describe("hi")`, "greeting"},
+		{shapes + "describe(null)", "nothing"},
+		{shapes + "describe([])", "empty"},
+		{shapes + "describe([5])", "one"},
+		{shapes + "describe([1, [2, 3]])", "nested"},
+		{shapes + "describe([1, 2])", "other"},
+		{shapes + `describe({ "name" = "fener", "age" = 1 })`, "fener"},
+		{shapes + "describe(Point(0, 0))", "origin"},
+		{shapes + "describe(Point(2, 2))", "diagonal"},
+		{shapes + "describe(Point(1, 7))", 7},
+		{shapes + "describe(101)", "big"},
+		{shapes + "describe(5)", "other"},
+		{"match [1, 2] case [a, b] then a + b end", 3},
+		{"x = 1 match 2 case y then x = y end x", 2},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"match 5 case 1 then 1 end", "No case matches int(5)"},
+		{"match 5 case x if x > 10 then 1 end", "No case matches int(5)"},
+		{"match 5 case a + b then 1 end", "Invalid pattern (a + b)"},
+		{"x = 1 match 5 case x(y) then 1 end", "x in pattern is not a class"},
+		{"class P fn init(x) this.x = x end end match P(1) case P(a, b) then 1 end", "P() pattern takes 1 arguments, got 2"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	table := []testCase{
+		{"a = 1 b = 2 a, b = b, a a", 2},
+		{"a = 1 b = 2 a, b = b, a b", 1},
+		{"[x, y] = [3, 4] y", 4},
+		{"pair = [5, 6]\n[x, y] = pair\nx + y", 11},
+		{"x, y = [5, 6] x", 5},
+		{"[x, [y, z]] = [1, [2, 3]] z", 3},
+		{`{ "name" = n, "age" = a } = { "name" = "fener", "age" = 1 } n`, "fener"},
+		{"xs = [0, 0] xs[1], xs[2] = 7, 8 xs[2]", 8},
+		{"class P fn init() this.a = 0 end end p = P() p.a, q = 9, 10 p.a", 9},
+		{"fn divmod(a, b) return a / b, a % b end q, r = divmod(7, 2) r", 1},
+		{"fn first([a, _b]) a end first([1, 2])", 1},
+		{`fn name({ "name" = n }) n end name({ "name" = "x", "age" = 2 })`, "x"},
+		{"f = fn(k, [a, b] = [1, 2]) k + a + b end f(3)", 6},
+		{"x = 0 fn f() x, y = 1, 2 end f() x", 1},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"a, b = 1, 2, 3", "Expected 2 values to destructure, got 3"},
+		{"[a, b] = [1]", "Expected 2 values to destructure, got 1"},
+		{"a, b = 5", "Can't destructure INTEGER into a list"},
+		{`{ "x" = x } = [1]`, "Can't destructure ARRAY into a map"},
+		{`{ "x" = x } = { "y" = 1 }`, "Map has no key str(x) to destructure"},
+		{"const C = 1 a, C = 1, 2", "can't assign to constant C"},
+		{"fn first([a, b]) a end first([1])", "Expected 2 values to destructure, got 1"},
+		{"fn first([a, b]) a end first()", "first() missing argument [a, b]"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	table := []testCase{
+		{"i = 1 i += 2 i", 3},
+		{"i = 10 i -= 4", 6},
+		{"i = 3 i *= 4 i", 12},
+		{"i = 9 i /= 2 i", 4},
+		{"i = 9 i %= 4 i", 1},
+		{"i = 0 fn inc() i += 1 end inc() inc() i", 2},
+		{`m = { "a" = 2 } m["a"] *= 5 m["a"]`, 10},
+		{"class C fn init() this.count = 0 end fn inc() this.count += 1 end end c = C() c.inc() c.inc() c.count", 2},
+		{"calls = 0 fn at() calls += 1 return 1 end xs = [10] xs[at()] += 5 xs[1]", 15},
+		{"calls = 0 fn at() calls += 1 return 1 end xs = [10] xs[at()] += 5 calls", 1},
+		{"class V fn init(n) this.n = n end fn add(o) return V(this.n + o) end end v = V(1) v += 2 v.n", 3},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"y += 1", "Identifier not found: y"},
+		{"const C = 1 C += 1", "can't assign to constant C"},
+		{`s = "a" s -= 1`, "Can't perform infix operation on left expression *object.String"},
+		{`m = {} m["a"] += 1`, "Can't perform infix operation on left expression *object.Null"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	table := []testCase{
+		{"len(1..10)", 10},
+		{"len(1...10)", 9},
+		{"len(5..1)", 0},
+		{"len(1..9223372036854775807)", 9223372036854775807},
+		{"(1..10)[3]", 3},
+		{"r = 0...3 r[3]", 2},
+		{"n = 2 len(1..n + 1)", 3},
+		{"str(1..3)", "1..3"},
+		{"str((1..10)[2:4])", "2..4"},
+		{"1..3 == 1...4", true},
+		{"1..0 == 5...5", true},
+		{"total = 0 r = 1..4 i = 1 while i <= len(r) then total += r[i] i += 1 end total", 10},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`1.."a"`, "Range bounds must be integers, got INTEGER and STRING"},
+		{"(1..3)[4]", "Index 4 out of range [1, 3]"},
+		{"len(0..9223372036854775807)", "Range 0..9223372036854775807 has too many elements"},
+		{"map(0..9223372036854775807, fn(x) x end)", "Range 0..9223372036854775807 has too many elements"},
+		{"(-9223372036854775807)...9223372036854775807", "Range -9223372036854775807...9223372036854775807 has too many elements"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	table := []testCase{
+		{"str([1, 2, 3, 4][2:3])", "[2, 3]"},
+		{"str([1, 2, 3, 4][:2])", "[1, 2]"},
+		{"str([1, 2, 3, 4][3:])", "[3, 4]"},
+		{"str([1, 2, 3, 4][:])", "[1, 2, 3, 4]"},
+		{"str([1, 2, 3, 4][-2:])", "[3, 4]"},
+		{"str([1, 2, 3, 4][:-2])", "[1, 2, 3]"},
+		{"str([1, 2, 3, 4][3:2])", "[]"},
+		{"str([1, 2, 3, 4][2:10])", "[2, 3, 4]"},
+		{`"fener"[2:4]`, "ene"},
+		{`"fener"[-3:]`, "ner"},
+		{"xs = [1, 2] ys = xs[:] ys[1] = 5 xs[1]", 1},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2][0:1]", "Slice index can't be 0, indexes start at 1"},
+		{`[1, 2]["a":]`, "Slice index must be an integer, got *object.String"},
+		{`{ "a" = 1 }[1:]`, "Can't slice expression *object.Map"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestPipe(t *testing.T) {
+	table := []testCase{
+		{`"fener" |> upper()`, "FENER"},
+		{`"fener" |> upper`, "FENER"},
+		{"fn add(a, b) a + b end 1 |> add(2)", 3},
+		{"fn add(a, b) a + b end [1, 2, 3] |> len() |> add(1)", 4},
+		{"2 |> fn(x) x * 3 end", 6},
+		{"double = fn(x) x * 2 end 4 |> double |> double", 16},
+		{"class C fn init(n) this.n = n end fn plus(x) this.n + x end end c = C(1) 5 |> c.plus()", 6},
+		{"fn sub(a, b = 1) a - b end 5 |> sub(b: 2)", 3},
+		{"fn add(a, b) a + b end 1 + 2 |> add(3)", 6},
+		{`"ab" |> len() == 2`, true},
+		{"1..3 |> len()", 3},
+		{"a = null 1 |> a?.plus()", nil},
+	}
+
+	runTableTests(t, table)
+
+	checkError(t, "1 |> 2", "Can't call expression *object.Integer")
+}
+
+func TestCollections(t *testing.T) {
+	table := []testCase{
+		{"str(map([1, 2, 3], fn(x) x * 2 end))", "[2, 4, 6]"},
+		{`str(map(["a", "b"], upper))`, "[A, B]"},
+		{"str(map(1..3, fn(x) x * x end))", "[1, 4, 9]"},
+		{"str(filter(1..10, fn(x) x % 3 == 0 end))", "[3, 6, 9]"},
+		{"reduce([1, 2, 3, 4], fn(a, b) a + b end)", 10},
+		{"reduce([], fn(a, b) a + b end, 7)", 7},
+		{"reduce(1..4, fn(a, b) a * b end, 1)", 24},
+		{"str(sort([3, 1, 2]))", "[1, 2, 3]"},
+		{`str(sort(["b", "c", "a"]))`, "[a, b, c]"},
+		{"str(sort([3, 1, 2], fn(a, b) b - a end))", "[3, 2, 1]"},
+		{`str(sort_by(["ccc", "a", "bb", "d"], len))`, "[a, d, bb, ccc]"},
+		{"str(sort_by([[2, 1], [1, 2], [2, 3], [1, 4]], fn(p) p[1] end))", "[[1, 2], [1, 4], [2, 1], [2, 3]]"},
+		{"class P fn init(n) this.n = n end fn lt(o) this.n < o.n end end str(map(sort([P(2), P(1)]), fn(p) p.n end))", "[1, 2]"},
+		{"class K fn init(n) this.n = n end fn twice() this.n * 2 end end k = K(3) str(map([1], fn(x) k.twice() + x end))", "[7]"},
+		{"class V fn init(n) this.n = n end end str(map(map([1, 2], V), fn(v) v.n end))", "[1, 2]"},
+		{"[1, 2, 3] |> map(fn(x) x + 1 end) |> reduce(fn(a, b) a + b end)", 9},
+		{"xs = [2, 1] sort(xs) xs[1]", 2},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"map(1, upper)", "Error calling builtin function map: first argument to MAP must be a list, string or range, got INTEGER"},
+		{"reduce([], fn(a, b) a end)", "Error calling builtin function reduce: REDUCE of an empty array needs an initial value"},
+		{`sort([1, "a"])`, "Error calling builtin function sort: can't compare STRING and INTEGER"},
+		{"sort([1, 2], fn(a, b) true end)", "Error calling builtin function sort: comparator must return an integer, got BOOLEAN"},
+		{"filter([1], fn() true end)", "<lambda>() takes 0 arguments, got 1"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+
+	// A failing callback is reported once, not again by the builtin calling it.
+	err := evalWithBudget(t, `map([1], fn(x) x + "a" end)`, nil)
+
+	if strings.Contains(err.Error(), "Error calling builtin function") {
+		t.Errorf("Expected the callback's own error, got %v", err)
+	}
+}
+
 func TestLambda(t *testing.T) {
 
 	table := []testCase{
@@ -141,6 +958,167 @@ func TestFunction(t *testing.T) {
 	runTableTests(t, table)
 }
 
+func TestArguments(t *testing.T) {
+	table := []testCase{
+		{`fn greet(name, greeting = "Hi") greeting end greet("ada")`, "Hi"},
+		{`fn greet(name, greeting = "Hi") greeting end greet("ada", "Hey")`, "Hey"},
+		{`fn greet(name, greeting = "Hi") greeting end greet(greeting: "Yo", name: "ada")`, "Yo"},
+		{`fn greet(name, greeting = "Hi") name end greet(greeting: "Yo", name: "ada")`, "ada"},
+		{`fn greet(name, greeting = "Hi") greeting end greet(name: "x")`, "Hi"},
+		{"fn f(a, b = a * 2) b end f(4)", 8},
+		{"fn sum(...xs) len(xs) end sum()", 0},
+		{"fn sum(...xs) xs[1] + xs[2] end sum(1, 2)", 3},
+		{"fn f(a, ...xs) a + len(xs) end f(10, 1, 2, 3)", 13},
+		{"add = fn(a, b = 1) a + b end add(b: 2, a: 3)", 5},
+		{"class P fn init(x, y = 0) this.x = x this.y = y end end p = P(y: 2, x: 1) p.x + p.y", 3},
+		{"class P fn init(x, y = 5) this.y = y end end P(1).y", 5},
+		{"class P fn init(...xs) this.n = len(xs) end end P(1, 2).n", 2},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"fn f(a, b) a end f(1)", "f() missing argument b"},
+		{"fn f(a, b) a end f()", "f() missing arguments a, b"},
+		{"fn f(a) a end f(1, 2)", "f() takes 1 arguments, got 2"},
+		{"fn f(a) a end f(a: 1, b: 2)", "f() got an unexpected keyword argument b"},
+		{"fn f(a) a end f(1, a: 2)", "f() got multiple values for argument a"},
+		{"fn f(...xs) xs end f(xs: 1)", "f() got an unexpected keyword argument xs"},
+		{"g = fn(a) a end g()", "<lambda>() missing argument a"},
+		{"class P fn init(x) end end P()", "P.init() missing argument x"},
+		{"class P end P(x: 1)", "P() got an unexpected keyword argument x"},
+		{`len(x: "a")`, "len() doesn't take keyword arguments"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+
+	// Like the REPL, keep evaluating after an error, a failed default or init fails the call.
+	failed := []string{
+		"ran = false fn f(x = nope) ran = true end f()",
+		"ran = false class P fn init(x = nope) ran = true end end P()",
+		"class P fn init() this.x = nope end end P()",
+	}
+
+	for _, input := range failed {
+		t.Run(input, func(t *testing.T) {
+			program, _ := parse(input)
+
+			var errs []error
+			e := New(func(err error) { errs = append(errs, err) })
+
+			env := object.NewEnvironment()
+			result := e.Eval(program, env)
+
+			if len(errs) == 0 || result != nil {
+				t.Fatalf("Expected the call to fail, got %v (%v)", result, errs)
+			}
+
+			if ran, ok := env.Get("ran").(*object.Boolean); ok && ran.Value {
+				t.Fatalf("Expected the body not to run")
+			}
+		})
+	}
+}
+
+const vecClass = `
+class Vec
+    fn init(x, y) this.x = x this.y = y end
+    fn add(other) Vec(this.x + other.x, this.y + other.y) end
+    fn sub(other) Vec(this.x - other.x, this.y - other.y) end
+    fn eq(other) this.x == other.x && this.y == other.y end
+    fn lt(other) this.x < other.x end
+    fn str() "vec" end
+    fn len() 2 end
+    fn index(i) if i == 1 then this.x else this.y end end
+    fn call(n) this.x * n end
+    fn scale(n) Vec(this.x * n, this.y * n) end
+end
+a = Vec(1, 2)
+b = Vec(3, 4)
+`
+
+func TestOperatorMethods(t *testing.T) {
+	table := []testCase{
+		{vecClass + "c = a + b c.x", 4},
+		{vecClass + "c = b - a c.y", 2},
+		{vecClass + "a == Vec(1, 2)", true},
+		{vecClass + "a != b", true},
+		{vecClass + "a < b", true},
+		{vecClass + "a > b", false},
+		{vecClass + "b >= a", true},
+		{vecClass + "a <= a", true},
+		{vecClass + "len(a)", 2},
+		{vecClass + "b[2]", 4},
+		{vecClass + "a(10)", 10},
+		{vecClass + "s = a.scale(2) a.x + s.x", 3},
+	}
+
+	runTableTests(t, table)
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"class P end p = P() p(1)", "Can't call instance of P, it has no call method"},
+		{"class P end p = P() p[1]", "Can't index instance of P, it has no index method"},
+	}
+
+	for _, tt := range errors {
+		t.Run(tt.input, func(t *testing.T) {
+			checkError(t, tt.input, tt.expected)
+		})
+	}
+}
+
+func TestPrintInstances(t *testing.T) {
+	classes := `
+    class Named
+        fn init(name) this.name = name end
+        fn to_string() this.name end
+    end
+    class Point
+        fn init(x, y) this.y = y this.x = x end
+    end
+    `
+
+	tt := []struct {
+		input    string
+		expected string
+	}{
+		{vecClass + "print(a, [1])", "vec[1]\n"},
+		{classes + `print(Named("ada"), " ", [Named("bob")])`, "ada [bob]\n"},
+		{classes + `print(Point(1, Named("c")))`, "Point{x = 1, y = c}\n"},
+		{classes + `p = Point(1, 2) p.x = p print(p)`, "Point{x = Point{...}, y = 2}\n"},
+		{classes + `print(upper(str(Named("ada"))))`, "ADA\n"},
+	}
+
+	for _, tc := range tt {
+		program, errors := parse(tc.input)
+
+		if len(errors) > 0 {
+			t.Fatalf("Parsing failed: %v", errors)
+		}
+
+		var out strings.Builder
+
+		env := object.NewEnvironment()
+		env.Stdout = &out
+
+		New(func(err error) { t.Fatalf(err.Error()) }).Eval(program, env)
+
+		if out.String() != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, out.String())
+		}
+	}
+}
+
 func TestAssignment(t *testing.T) {
 	table := []testCase{
 		{"a = 5 a", 5},
@@ -222,6 +1200,35 @@ func TestInfix(t *testing.T) {
 	runTableTests(t, table)
 }
 
+func writeModule(t *testing.T, dir string, name string, contents string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Error creating module directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Error writing module: %v", err)
+	}
+}
+func checkFile(t *testing.T, file string, input string, expected interface{}) {
+	t.Helper()
+
+	ast, errors := parse(input)
+
+	if len(errors) > 0 {
+		t.Fatalf("Parsing failed: %v", errors)
+	}
+
+	e := New(func(err error) {
+		t.Fatalf(err.Error())
+	})
+	e.File = file
+
+	checkValue(t, e.Eval(ast, object.NewEnvironment()), expected)
+}
 func checkEval(t *testing.T, input string, expected interface{}) {
 	t.Helper()
 
@@ -243,6 +1250,45 @@ func checkEval(t *testing.T, input string, expected interface{}) {
 
 	value := e.Eval(ast, env)
 
+	checkValue(t, value, expected)
+}
+func checkError(t *testing.T, input string, expected string) {
+	t.Helper()
+
+	ast, errors := parse(input)
+
+	if len(errors) > 0 {
+		t.Fatalf("Parsing failed: %v", errors)
+	}
+
+	// Evaluation continues after an error, so the first one stops it like the CLI does.
+	type halt struct{ err error }
+
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				h, ok := r.(halt)
+				if !ok {
+					panic(r)
+				}
+				err = h.err
+			}
+		}()
+
+		e := New(func(err error) {
+			panic(halt{err: err})
+		})
+		e.Eval(ast, object.NewEnvironment())
+	}()
+
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q, got %v", expected, err)
+	}
+}
+func checkValue(t *testing.T, value object.Object, expected interface{}) {
+	t.Helper()
+
 	switch expected := expected.(type) {
 	case int:
 		checkIntegerObject(t, value, int64(expected))
@@ -0,0 +1,241 @@
+package eval
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/parser"
+	"github.com/pspiagicw/fener/stdlib"
+)
+
+const moduleExtension = ".fn"
+
+// Loader finds, loads and caches modules for import statements.
+type Loader struct {
+	Paths []string
+
+	modules map[string]*object.Module
+	loading []string
+}
+
+func NewLoader(paths []string) *Loader {
+	return &Loader{
+		Paths:   paths,
+		modules: make(map[string]*object.Module),
+		loading: []string{},
+	}
+}
+
+// SearchPath returns the directories listed in FENER_PATH.
+func SearchPath() []string {
+	paths := []string{}
+
+	for _, path := range filepath.SplitList(os.Getenv("FENER_PATH")) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+type source struct {
+	key      string
+	filename string
+	contents []byte
+}
+
+// find resolves an import path. Paths starting with ./ or ../ are only looked up
+// relative to the importing file, everything else is searched for in the
+// importing file's directory, then FENER_PATH and finally the standard library.
+// Only the standard library can be imported without the fs-read capability.
+func (l *Loader) find(path string, dir string, caps object.Capabilities) (*source, error) {
+	name := path
+	if filepath.Ext(name) != moduleExtension {
+		name += moduleExtension
+	}
+
+	relative := strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../")
+
+	if !caps.Allows(object.FS_READ_CAP) {
+		if !relative && !filepath.IsAbs(name) {
+			if src, err := stdlibSource(name); err == nil {
+				return src, nil
+			}
+		}
+		return nil, &object.PermissionError{Builtin: "import", Capability: object.FS_READ_CAP}
+	}
+
+	if filepath.IsAbs(name) {
+		return readSource(name)
+	}
+
+	if relative {
+		return readSource(filepath.Join(dir, name))
+	}
+
+	for _, base := range append([]string{dir}, l.Paths...) {
+		if src, err := readSource(filepath.Join(base, name)); err == nil {
+			return src, nil
+		}
+	}
+
+	src, err := stdlibSource(name)
+
+	if err != nil {
+		return nil, fmt.Errorf("module %q not found", path)
+	}
+
+	return src, nil
+}
+
+func stdlibSource(name string) (*source, error) {
+	contents, err := fs.ReadFile(stdlib.FS, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &source{key: "stdlib:" + name, filename: name, contents: contents}, nil
+}
+
+func readSource(filename string) (*source, error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &source{key: abs, filename: abs, contents: contents}, nil
+}
+
+func (l *Loader) isLoading(key string) bool {
+	for _, k := range l.loading {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Loader) cycle(key string) string {
+	chain := []string{}
+	start := 0
+
+	for i, k := range l.loading {
+		if k == key {
+			start = i
+		}
+	}
+
+	for _, k := range l.loading[start:] {
+		chain = append(chain, moduleName(k))
+	}
+	chain = append(chain, moduleName(key))
+
+	return strings.Join(chain, " -> ")
+}
+
+func moduleName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), moduleExtension)
+}
+
+func (e *Evaluator) dir() string {
+	if e.File == "" {
+		return "."
+	}
+	return filepath.Dir(e.File)
+}
+
+func (e *Evaluator) importModule(path string, importer *object.Environment) (*object.Module, error) {
+	src, err := e.Loader.find(path, e.dir(), importer.Root().Capabilities)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if module, ok := e.Loader.modules[src.key]; ok {
+		return module, nil
+	}
+
+	if e.Loader.isLoading(src.key) {
+		return nil, fmt.Errorf("import cycle detected: %s", e.Loader.cycle(src.key))
+	}
+
+	e.Loader.loading = append(e.Loader.loading, src.key)
+	defer func() {
+		e.Loader.loading = e.Loader.loading[:len(e.Loader.loading)-1]
+	}()
+
+	l := lexer.New(string(src.contents))
+	p := parser.New(l)
+	program := p.Parse()
+
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("parsing module %q failed: %s", path, strings.Join(p.Errors(), ", "))
+	}
+
+	env := object.NewSiblingEnvironment(importer)
+
+	module := &object.Module{
+		Name: moduleName(src.filename),
+		Path: src.filename,
+		Env:  env,
+	}
+
+	failed := false
+
+	sub := &Evaluator{
+		ErrorHandler: func(err error) {
+			failed = true
+			e.ErrorHandler(err)
+		},
+		Test:   e.Test,
+		File:   src.filename,
+		Loader: e.Loader,
+		Budget: e.Budget,
+		Hooks:  e.Hooks,
+	}
+
+	sub.Eval(program, env)
+
+	// A module that failed is evaluated again by the next import instead of sharing its broken state.
+	if failed {
+		return nil, fmt.Errorf("evaluating module %q failed", path)
+	}
+
+	e.Loader.modules[src.key] = module
+
+	return module, nil
+}
+
+func (e *Evaluator) evalImportStatement(node *ast.ImportStatement, env *object.Environment) object.Object {
+	module, err := e.importModule(node.Path.Value, env)
+
+	if err != nil {
+		e.Error("Error importing %s: %s", node.Path.Value, err)
+		return nil
+	}
+
+	name := module.Name
+
+	if node.Alias != nil {
+		name = node.Alias.Value
+	}
+
+	if err := env.Define(name, module); err != nil {
+		e.Error("%s", err)
+		return nil
+	}
+
+	return &object.Null{}
+}
@@ -0,0 +1,158 @@
+package eval
+
+import (
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/object"
+)
+
+// evalMatchExpression runs the first case whose pattern matches, in an
+// environment holding the names bound by the pattern.
+func (e *Evaluator) evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	value := e.Eval(node.Value, env)
+	if value == nil {
+		return nil
+	}
+
+	for _, c := range node.Cases {
+		caseEnv := newEnclosedEnvironment(env)
+
+		matched, ok := e.matchPattern(c.Pattern, value, caseEnv)
+		if !ok {
+			return nil
+		}
+
+		if !matched {
+			continue
+		}
+
+		if c.Guard != nil && !isTruthy(e.Eval(c.Guard, caseEnv)) {
+			continue
+		}
+
+		return e.Eval(c.Body, caseEnv)
+	}
+
+	e.Error("No case matches %s", value.String())
+	return nil
+}
+
+// matchPattern binds the names in pattern to the parts of value in env, ok is false after an error.
+func (e *Evaluator) matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) (matched bool, ok bool) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, value)
+		}
+		return true, true
+	case *ast.Integer, *ast.String, *ast.Boolean, *ast.Null, *ast.PrefixExpression:
+		expected := e.Eval(pattern, env)
+		if expected == nil {
+			return false, false
+		}
+		return e.isEqual(value, expected), true
+	case *ast.Array:
+		return e.matchArray(pattern, value, env)
+	case *ast.Map:
+		return e.matchMap(pattern, value, env)
+	case *ast.CallExpression:
+		return e.matchClass(pattern, value, env)
+	default:
+		e.Error("Invalid pattern %s", pattern.String())
+		return false, false
+	}
+}
+
+func (e *Evaluator) matchArray(pattern *ast.Array, value object.Object, env *object.Environment) (bool, bool) {
+	array, ok := value.(*object.Array)
+	if !ok || len(array.Elements) != len(pattern.Elements) {
+		return false, true
+	}
+
+	for i, element := range pattern.Elements {
+		if matched, ok := e.matchPattern(element, array.Elements[i], env); !matched || !ok {
+			return false, ok
+		}
+	}
+
+	return true, true
+}
+
+// matchMap matches maps having at least the keys of the pattern.
+func (e *Evaluator) matchMap(pattern *ast.Map, value object.Object, env *object.Environment) (bool, bool) {
+	m, ok := value.(*object.Map)
+	if !ok {
+		return false, true
+	}
+
+	for i, key := range pattern.Keys {
+		k := e.Eval(key, env)
+		if k == nil {
+			return false, false
+		}
+
+		v, ok := m.Get(k)
+		if !ok {
+			return false, true
+		}
+
+		if matched, ok := e.matchPattern(pattern.Values[i], v, env); !matched || !ok {
+			return false, ok
+		}
+	}
+
+	return true, true
+}
+
+// matchClass matches instances of the class, positional patterns are matched
+// against the fields named by the parameters of init, keyword ones by name.
+func (e *Evaluator) matchClass(pattern *ast.CallExpression, value object.Object, env *object.Environment) (bool, bool) {
+	ident, ok := pattern.Function.(*ast.Identifier)
+	if !ok {
+		e.Error("Invalid pattern %s", pattern.String())
+		return false, false
+	}
+
+	klass, ok := env.Get(ident.Value).(*object.Class)
+	if !ok {
+		e.Error("%s in pattern is not a class", ident.Value)
+		return false, false
+	}
+
+	fields := []string{}
+	if init, ok := klass.Methods["init"]; ok {
+		fields = init.Arguments
+	}
+
+	instance, ok := value.(*object.Instance)
+	if !ok || instance.Class != klass {
+		return false, true
+	}
+
+	positional := 0
+	for _, arg := range pattern.Arguments {
+		name := ""
+		sub := arg
+
+		if keyword, ok := arg.(*ast.KeywordArgument); ok {
+			name, sub = keyword.Key, keyword.Value
+		} else {
+			if positional >= len(fields) {
+				e.Error("%s() pattern takes %d arguments, got %d", klass.Name, len(fields), len(pattern.Arguments))
+				return false, false
+			}
+			name = fields[positional]
+			positional++
+		}
+
+		field, ok := instance.Map[name]
+		if !ok {
+			return false, true
+		}
+
+		if matched, ok := e.matchPattern(sub, field, env); !matched || !ok {
+			return false, ok
+		}
+	}
+
+	return true, true
+}
@@ -0,0 +1,56 @@
+package eval
+
+import (
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/token"
+)
+
+// evalRange builds `start..end`, or `start...end` leaving the end out.
+func (e *Evaluator) evalRange(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	right := e.Eval(node.Right, env)
+	if left == nil || right == nil {
+		return nil
+	}
+
+	start, end := toInteger(left), toInteger(right)
+	if start == nil || end == nil {
+		e.Error("Range bounds must be integers, got %s and %s", left.Type(), right.Type())
+		return nil
+	}
+
+	r, err := object.NewRange(start.Value, end.Value, node.Operator == token.ELLIPSIS)
+	if err != nil {
+		e.Error("%s", err)
+		return nil
+	}
+
+	return r
+}
+
+func (e *Evaluator) evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := e.Eval(node.Left, env)
+	if left == nil {
+		return nil
+	}
+
+	var start, end object.Object
+	if node.Start != nil {
+		if start = e.Eval(node.Start, env); start == nil {
+			return nil
+		}
+	}
+	if node.End != nil {
+		if end = e.Eval(node.End, env); end == nil {
+			return nil
+		}
+	}
+
+	value, err := object.Slice(left, start, end)
+	if err != nil {
+		e.Error("%s", err)
+		return nil
+	}
+	return value
+}
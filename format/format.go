@@ -26,7 +26,7 @@ func Handle(opts *argparse.Opts) {
 			goreland.LogFatal("Parsing failed!!!")
 		}
 
-		output := format(ast)
+		output := Format(ast)
 
 		fmt.Println(output)
 	}
@@ -45,7 +45,9 @@ func parseFile(filename string) (*ast.Program, []string) {
 	return program, p.Errors()
 
 }
-func format(program *ast.Program) string {
+
+// Format renders program in the canonical style.
+func Format(program *ast.Program) string {
 	var out strings.Builder
 	for _, s := range program.Statements {
 		out.WriteString(s.String())
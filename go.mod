@@ -3,17 +3,16 @@ module github.com/pspiagicw/fener
 go 1.21.4
 
 require (
+	github.com/chzyer/readline v1.5.1
 	github.com/davecgh/go-spew v0.0.0-20161028175848-04cdfd42973b
 	github.com/pspiagicw/goreland v0.0.0-20231129143657-4b9ff8ac7ad5
 	github.com/pspiagicw/pelp v0.0.0-20240318161701-7448ea2e376e
-	github.com/pspiagicw/regolith v0.0.0-20240529080134-2bef52bba263
 	github.com/sanity-io/litter v1.5.5
 )
 
 require (
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/charmbracelet/lipgloss v0.10.0 // indirect
-	github.com/chzyer/readline v1.5.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-isatty v0.0.18 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
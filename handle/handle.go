@@ -2,8 +2,11 @@ package handle
 
 import (
 	"github.com/pspiagicw/fener/argparse"
+	"github.com/pspiagicw/fener/debug"
 	"github.com/pspiagicw/fener/format"
 	"github.com/pspiagicw/fener/help"
+	"github.com/pspiagicw/fener/lint"
+	"github.com/pspiagicw/fener/lsp"
 	"github.com/pspiagicw/fener/repl"
 	"github.com/pspiagicw/fener/run"
 	"github.com/pspiagicw/goreland"
@@ -19,6 +22,9 @@ var handles = map[string]func(*argparse.Opts){
 		help.Handle(opts.Args, opts.Version)
 	},
 	"format": format.Handle,
+	"lsp":    lsp.Entry,
+	"debug":  debug.Entry,
+	"lint":   lint.Entry,
 }
 
 func Handle(opts *argparse.Opts) {
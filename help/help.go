@@ -9,8 +9,8 @@ func Usage() {
 
 	pelp.Aligned(
 		"commands",
-		[]string{"help", "run", "repl", "version"},
-		[]string{"Show this help message", "Run a file", "Start a repl", "Show version"},
+		[]string{"help", "run", "repl", "format", "lint", "lsp", "debug", "version"},
+		[]string{"Show this help message", "Run a file", "Start a repl", "Format files", "Report likely bugs in files", "Start the language server on stdio", "Debug a file over the Debug Adapter Protocol on stdio", "Show version"},
 	)
 }
 func Version(version string) {
@@ -31,17 +31,47 @@ func Handle(args []string, version string) {
 		Repl()
 	case "run":
 		Run()
+	case "debug":
+		Debug()
+	case "lint":
+		Lint()
 	}
 }
 
 func Run() {
 	pelp.Print("Run a file")
 
-	pelp.Flags("flags", []string{"print-ast"}, []string{"Print the AST of the program"})
+	pelp.Flags(
+		"flags",
+		[]string{"print-ast", "allow"},
+		[]string{"Print the AST of the program", "Grant capabilities to builtins (fs-read, fs-write, env, exec, net, clock, random, all)"},
+	)
 }
 
 func Repl() {
 	pelp.Print("Start fener repl")
 
 	pelp.Flags("flags", []string{"print-ast"}, []string{"Print the AST of the program"})
+
+	pelp.Aligned(
+		"commands",
+		[]string{":help", ":env", ":ast", ":tokens", ":load <file>", ":save <file>", ":snapshot <file>", ":restore <file>", ":reset", ":time", ":quit"},
+		[]string{"Show the available commands", "List the bindings and their types", "Toggle printing the AST", "Toggle printing the tokens", "Evaluate a file into the session", "Write the successful inputs to a replayable file", "Write the plain data bindings to a JSON snapshot", "Replay a saved session or load a .json snapshot", "Clear all bindings", "Toggle timing every evaluation", "Exit the repl"},
+	)
+}
+
+func Debug() {
+	pelp.Print("Debug a file over the Debug Adapter Protocol on stdio")
+
+	pelp.Flags(
+		"flags",
+		[]string{"allow"},
+		[]string{"Grant capabilities to builtins (fs-read, fs-write, env, exec, net, clock, random, all)"},
+	)
+}
+
+func Lint() {
+	pelp.Print("Report likely bugs in files")
+
+	pelp.Flags("flags", []string{"json"}, []string{"Print the issues as JSON"})
 }
@@ -2,6 +2,8 @@ package lexer
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pspiagicw/fener/token"
 )
@@ -98,37 +100,50 @@ func (l *Lexer) Error() error {
 	return l.err
 }
 
+var keywords = map[string]token.TokenType{
+	"if":      token.IF,
+	"else":    token.ELSE,
+	"while":   token.WHILE,
+	"false":   token.FALSE,
+	"true":    token.TRUE,
+	"null":    token.NULL,
+	"fn":      token.FUNCTION,
+	"return":  token.RETURN,
+	"end":     token.END,
+	"not":     token.NOT,
+	"then":    token.THEN,
+	"elif":    token.ELIF,
+	"test":    token.TEST,
+	"class":   token.CLASS,
+	"import":  token.IMPORT,
+	"as":      token.AS,
+	"let":     token.LET,
+	"const":   token.CONST,
+	"static":  token.STATIC,
+	"trait":   token.TRAIT,
+	"include": token.INCLUDE,
+	"match":   token.MATCH,
+	"case":    token.CASE,
+}
+
+// Keywords returns every reserved word in sorted order.
+func Keywords() []string {
+	words := []string{}
+
+	for word := range keywords {
+		words = append(words, word)
+	}
+
+	sort.Strings(words)
+
+	return words
+}
+
 func (l *Lexer) keyword(ident string) *token.Token {
-	switch ident {
-	case "if":
-		return l.token(token.IF, "if")
-	case "else":
-		return l.token(token.ELSE, "else")
-	case "while":
-		return l.token(token.WHILE, "while")
-	case "false":
-		return l.token(token.FALSE, "false")
-	case "true":
-		return l.token(token.TRUE, "true")
-	case "fn":
-		return l.token(token.FUNCTION, "fn")
-	case "return":
-		return l.token(token.RETURN, "return")
-	case "end":
-		return l.token(token.END, "end")
-	case "not":
-		return l.token(token.NOT, "not")
-	case "then":
-		return l.token(token.THEN, "then")
-	case "elif":
-		return l.token(token.ELIF, "elif")
-	case "test":
-		return l.token(token.TEST, "test")
-	case "class":
-		return l.token(token.CLASS, "class")
-	default:
-		return l.token(token.IDENT, ident)
+	if t, ok := keywords[ident]; ok {
+		return l.token(t, ident)
 	}
+	return l.token(token.IDENT, ident)
 }
 
 func (l *Lexer) peek() string {
@@ -138,6 +153,11 @@ func (l *Lexer) peek() string {
 	return ""
 }
 
+// lookahead reports whether the input after the current char starts with s.
+func (l *Lexer) lookahead(s string) bool {
+	return l.readPosition < len(l.input) && strings.HasPrefix(l.input[l.readPosition:], s)
+}
+
 func (l *Lexer) whitespace() {
 	for l.ch == " " || l.ch == "\t" || l.ch == "\n" || l.ch == "\r" {
 		if l.ch == "\n" {
@@ -180,9 +200,34 @@ func (l *Lexer) Next() *token.Token {
 			l.advance()
 			return l.token(token.OR, "||")
 		}
+		if l.peek() == ">" {
+			l.advance()
+			return l.token(token.PIPE, "|>")
+		}
 		return l.token(token.BITOR, l.ch)
 	case ".":
+		if l.lookahead("..") {
+			l.advance()
+			l.advance()
+			return l.token(token.ELLIPSIS, "...")
+		}
+		if l.peek() == "." {
+			l.advance()
+			return l.token(token.RANGE, "..")
+		}
 		return l.token(token.DOT, ".")
+	case "?":
+		if l.peek() == "." {
+			l.advance()
+			return l.token(token.OPTIONAL_DOT, "?.")
+		}
+		if l.peek() == "?" {
+			l.advance()
+			return l.token(token.COALESCE, "??")
+		}
+		return l.token(token.ILLEGAL, l.ch)
+	case ":":
+		return l.token(token.COLON, ":")
 	case ",":
 		return l.token(token.COMMA, ",")
 	case "(":
@@ -193,15 +238,39 @@ func (l *Lexer) Next() *token.Token {
 		return l.token(token.LSQUARE, "[")
 	case "]":
 		return l.token(token.RSQUARE, "]")
+	case "{":
+		return l.token(token.LBRACE, "{")
+	case "}":
+		return l.token(token.RBRACE, "}")
 	case "+":
+		if l.peek() == "=" {
+			l.advance()
+			return l.token(token.PLUS_ASSIGN, "+=")
+		}
 		return l.token(token.PLUS, "+")
 	case "-":
+		if l.peek() == "=" {
+			l.advance()
+			return l.token(token.MINUS_ASSIGN, "-=")
+		}
 		return l.token(token.MINUS, "-")
 	case "*":
+		if l.peek() == "=" {
+			l.advance()
+			return l.token(token.MULTIPLY_ASSIGN, "*=")
+		}
 		return l.token(token.MULTIPLY, "*")
 	case "/":
+		if l.peek() == "=" {
+			l.advance()
+			return l.token(token.DIVIDE_ASSIGN, "/=")
+		}
 		return l.token(token.DIVIDE, "/")
 	case "%":
+		if l.peek() == "=" {
+			l.advance()
+			return l.token(token.MOD_ASSIGN, "%=")
+		}
 		return l.token(token.MOD, "%")
 	case ";":
 		if l.peek() == ";" {
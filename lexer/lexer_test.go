@@ -53,13 +53,15 @@ import "github.com/pspiagicw/fener/token"
 //	}
 
 func TestParentheses(t *testing.T) {
-	input := `() []`
+	input := `() [] {}`
 
 	expectedTokens := []token.Token{
 		{Type: token.LPAREN, Value: "("},
 		{Type: token.RPAREN, Value: ")"},
 		{Type: token.LSQUARE, Value: "["},
 		{Type: token.RSQUARE, Value: "]"},
+		{Type: token.LBRACE, Value: "{"},
+		{Type: token.RBRACE, Value: "}"},
 		{Type: token.EOF, Value: ""},
 	}
 
@@ -118,7 +120,7 @@ func TestCommentToken(t *testing.T) {
 
 func TestSymbolTokens(t *testing.T) {
 	// Test case for symbols
-	input := "+-*/=!%"
+	input := "+-*/ =!%"
 
 	expectedTokens := []token.Token{
 		{Type: token.PLUS, Value: "+"},
@@ -133,6 +135,97 @@ func TestSymbolTokens(t *testing.T) {
 	checkTokens(t, expectedTokens, input)
 }
 
+func TestCompoundAssignmentTokens(t *testing.T) {
+	input := "+= -= *= /= %= a-=-1"
+
+	expectedTokens := []token.Token{
+		{Type: token.PLUS_ASSIGN, Value: "+="},
+		{Type: token.MINUS_ASSIGN, Value: "-="},
+		{Type: token.MULTIPLY_ASSIGN, Value: "*="},
+		{Type: token.DIVIDE_ASSIGN, Value: "/="},
+		{Type: token.MOD_ASSIGN, Value: "%="},
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.MINUS_ASSIGN, Value: "-="},
+		{Type: token.MINUS, Value: "-"},
+		{Type: token.INT, Value: "1"},
+		{Type: token.EOF, Value: ""},
+	}
+	checkTokens(t, expectedTokens, input)
+}
+
+func TestParameterTokens(t *testing.T) {
+	input := "(a, ...xs) f(name: x.y)"
+
+	expectedTokens := []token.Token{
+		{Type: token.LPAREN, Value: "("},
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.COMMA, Value: ","},
+		{Type: token.ELLIPSIS, Value: "..."},
+		{Type: token.IDENT, Value: "xs"},
+		{Type: token.RPAREN, Value: ")"},
+		{Type: token.IDENT, Value: "f"},
+		{Type: token.LPAREN, Value: "("},
+		{Type: token.IDENT, Value: "name"},
+		{Type: token.COLON, Value: ":"},
+		{Type: token.IDENT, Value: "x"},
+		{Type: token.DOT, Value: "."},
+		{Type: token.IDENT, Value: "y"},
+		{Type: token.RPAREN, Value: ")"},
+		{Type: token.EOF, Value: ""},
+	}
+	checkTokens(t, expectedTokens, input)
+}
+
+func TestRangeTokens(t *testing.T) {
+	input := "1..2 a...b xs[1:]"
+
+	expectedTokens := []token.Token{
+		{Type: token.INT, Value: "1"},
+		{Type: token.RANGE, Value: ".."},
+		{Type: token.INT, Value: "2"},
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.ELLIPSIS, Value: "..."},
+		{Type: token.IDENT, Value: "b"},
+		{Type: token.IDENT, Value: "xs"},
+		{Type: token.LSQUARE, Value: "["},
+		{Type: token.INT, Value: "1"},
+		{Type: token.COLON, Value: ":"},
+		{Type: token.RSQUARE, Value: "]"},
+		{Type: token.EOF, Value: ""},
+	}
+	checkTokens(t, expectedTokens, input)
+}
+
+func TestPipeTokens(t *testing.T) {
+	input := "a |> f || b | c"
+
+	expectedTokens := []token.Token{
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.PIPE, Value: "|>"},
+		{Type: token.IDENT, Value: "f"},
+		{Type: token.OR, Value: "||"},
+		{Type: token.IDENT, Value: "b"},
+		{Type: token.BITOR, Value: "|"},
+		{Type: token.IDENT, Value: "c"},
+		{Type: token.EOF, Value: ""},
+	}
+	checkTokens(t, expectedTokens, input)
+}
+
+func TestNullTokens(t *testing.T) {
+	input := "a?.b ?? null"
+
+	expectedTokens := []token.Token{
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.OPTIONAL_DOT, Value: "?."},
+		{Type: token.IDENT, Value: "b"},
+		{Type: token.COALESCE, Value: "??"},
+		{Type: token.NULL, Value: "null"},
+		{Type: token.EOF, Value: ""},
+	}
+	checkTokens(t, expectedTokens, input)
+}
+
 func TestIdentifierTokens(t *testing.T) {
 	// Test case for identifiers
 	input := "foo bar baz foobar"
@@ -149,7 +242,7 @@ func TestIdentifierTokens(t *testing.T) {
 
 func TestKeywordTokens(t *testing.T) {
 	// Test case for keywords
-	input := "if else while false true return fn end not then elif test class"
+	input := "if else while false true return fn end not then elif test class import as"
 
 	expectedTokens := []token.Token{
 		{Type: token.IF, Value: "if"},
@@ -165,6 +258,8 @@ func TestKeywordTokens(t *testing.T) {
 		{Type: token.ELIF, Value: "elif"},
 		{Type: token.TEST, Value: "test"},
 		{Type: token.CLASS, Value: "class"},
+		{Type: token.IMPORT, Value: "import"},
+		{Type: token.AS, Value: "as"},
 		{Type: token.EOF, Value: ""},
 	}
 	checkTokens(t, expectedTokens, input)
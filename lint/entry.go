@@ -0,0 +1,80 @@
+package lint
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pspiagicw/fener/argparse"
+	"github.com/pspiagicw/fener/help"
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/parser"
+	"github.com/pspiagicw/goreland"
+)
+
+func parseLintArgs(opts *argparse.Opts) bool {
+	flag := flag.NewFlagSet("fener lint", flag.ExitOnError)
+
+	flag.Usage = help.Lint
+
+	asJSON := flag.Bool("json", false, "Print the issues as JSON")
+
+	flag.Parse(opts.Args)
+
+	opts.Args = flag.Args()
+
+	return *asJSON
+}
+
+func Entry(opts *argparse.Opts) {
+	asJSON := parseLintArgs(opts)
+
+	if len(opts.Args) == 0 {
+		goreland.LogFatal("No files to lint")
+	}
+
+	issues := []Issue{}
+
+	for _, file := range opts.Args {
+		issues = append(issues, File(file)...)
+	}
+
+	if asJSON {
+		out, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			goreland.LogFatal("Error encoding issues: %v", err)
+		}
+		fmt.Println(string(out))
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+	}
+
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+// File lints a file, syntax errors are reported instead of resolving it.
+func File(file string) []Issue {
+	contents, err := os.ReadFile(file)
+
+	if err != nil {
+		goreland.LogFatal("Error reading file: %v", err)
+	}
+
+	p := parser.New(lexer.New(string(contents)))
+	program := p.Parse()
+
+	if len(p.Diagnostics()) > 0 {
+		issues := []Issue{}
+		for _, d := range p.Diagnostics() {
+			issues = append(issues, Issue{File: file, Line: d.Line + 1, Rule: SYNTAX, Message: d.Message})
+		}
+		return issues
+	}
+
+	return Check(file, program)
+}
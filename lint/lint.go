@@ -0,0 +1,583 @@
+// Package lint finds likely bugs in fener programs without running them.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/object"
+)
+
+const (
+	UNDEFINED               = "undefined"
+	USE_BEFORE_DEFINITION   = "use-before-definition"
+	UNUSED_VARIABLE         = "unused-variable"
+	UNUSED_PARAMETER        = "unused-parameter"
+	ARGUMENT_COUNT          = "argument-count"
+	RETURN_OUTSIDE_FUNCTION = "return-outside-function"
+	SHADOWED_BUILTIN        = "shadowed-builtin"
+	SYNTAX                  = "syntax"
+)
+
+// Issue is a single problem found in a file, lines start at 1.
+type Issue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", i.File, i.Line, i.Message, i.Rule)
+}
+
+type kind int
+
+const (
+	variable kind = iota
+	parameter
+	function
+	class
+	module
+)
+
+type binding struct {
+	name    string
+	kind    kind
+	line    int
+	defined bool
+	used    bool
+	// arity is the number of arguments a function or class takes, nil if unknown.
+	arity *arity
+}
+
+// arity is the range of argument counts a call can pass.
+type arity struct {
+	min int
+	// max is -1 when a rest parameter takes any number of arguments.
+	max int
+}
+
+func (a *arity) allows(count int) bool {
+	return count >= a.min && (a.max < 0 || count <= a.max)
+}
+
+func (a *arity) String() string {
+	switch {
+	case a.max < 0:
+		return fmt.Sprintf("at least %d %s", a.min, plural(a.min, "argument"))
+	case a.min == a.max:
+		return fmt.Sprintf("%d %s", a.min, plural(a.min, "argument"))
+	default:
+		return fmt.Sprintf("%d to %d arguments", a.min, a.max)
+	}
+}
+
+type scope struct {
+	parent   *scope
+	bindings map[string]*binding
+	// global bindings may be used by importers, they are never reported as unused.
+	global bool
+}
+
+// testHelpers are used by test blocks, they are provided when tests are run.
+var testHelpers = map[string]bool{"assert": true, "capture": true}
+
+type resolver struct {
+	file     string
+	scope    *scope
+	builtins map[string]bool
+	issues   []Issue
+	// functions counts the functions being resolved, returns are only allowed inside one.
+	functions int
+	tests     int
+}
+
+// Check resolves every name in program and reports the issues sorted by line.
+func Check(file string, program *ast.Program) []Issue {
+	r := &resolver{file: file, builtins: map[string]bool{}}
+
+	for _, name := range object.BuiltinNames() {
+		r.builtins[name] = true
+	}
+
+	r.scope = &scope{bindings: map[string]*binding{}, global: true}
+	r.declare(program)
+
+	for _, stmt := range program.Statements {
+		r.resolve(stmt)
+	}
+
+	sort.SliceStable(r.issues, func(i, j int) bool {
+		if r.issues[i].Line != r.issues[j].Line {
+			return r.issues[i].Line < r.issues[j].Line
+		}
+		return r.issues[i].Message < r.issues[j].Message
+	})
+
+	return r.issues
+}
+
+func (r *resolver) report(line int, rule string, message string, args ...interface{}) {
+	r.issues = append(r.issues, Issue{File: r.file, Line: line + 1, Rule: rule, Message: fmt.Sprintf(message, args...)})
+}
+
+// declare collects the names bound in a scope before resolving it, so uses can
+// tell a name assigned later in the same scope from one that doesn't exist.
+func (r *resolver) declare(node ast.Node) {
+	add := func(name string, k kind, line int, a *arity) {
+		if b, ok := r.scope.bindings[name]; ok {
+			// Rebinding a name makes its arity unknown.
+			if b.arity == nil || a == nil || *b.arity != *a {
+				b.arity = nil
+			}
+			return
+		}
+		r.scope.bindings[name] = &binding{name: name, kind: k, line: line, arity: a}
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FunctionStatement:
+			if n.Target != nil {
+				add(n.Target.Value, function, n.Token.Line, functionArity(n))
+			}
+			return false
+		case *ast.ClassStatement:
+			if n.Target != nil {
+				add(n.Target.Value, class, n.Token.Line, initArity(n))
+			}
+			return false
+		case *ast.TraitStatement:
+			if n.Target != nil {
+				add(n.Target.Value, class, n.Token.Line, nil)
+			}
+			return false
+		case *ast.ImportStatement:
+			if name := importName(n); name != "" {
+				add(name, module, n.Token.Line, nil)
+			}
+			return false
+		case *ast.LetStatement:
+			if n.Target != nil {
+				add(n.Target.Value, variable, n.Target.Token.Line, nil)
+			}
+		case *ast.ConstStatement:
+			if n.Target != nil {
+				add(n.Target.Value, variable, n.Target.Token.Line, nil)
+			}
+		case *ast.AssignmentExpression:
+			// Assigning a name bound in an enclosing scope updates it instead of declaring a local.
+			for _, ident := range targetNames(n.Target) {
+				if r.scope.parent.find(ident.Value) == nil {
+					add(ident.Value, variable, ident.Token.Line, nil)
+				}
+			}
+		case *ast.Lambda, *ast.TestStatement, *ast.MatchCase:
+			return false
+		}
+		return true
+	})
+}
+
+func initArity(node *ast.ClassStatement) *arity {
+	for _, method := range node.Methods {
+		if method != nil && method.Target != nil && method.Target.Value == "init" {
+			return functionArity(method)
+		}
+	}
+	return nil
+}
+
+// functionArity counts the parameters without a default as required, a rest parameter lifts the maximum.
+func functionArity(node *ast.FunctionStatement) *arity {
+	a := &arity{min: len(node.Arguments) - len(node.Defaults), max: len(node.Arguments)}
+	if node.Rest != nil {
+		a.max = -1
+	}
+	return a
+}
+
+func importName(node *ast.ImportStatement) string {
+	if node.Alias != nil {
+		return node.Alias.Value
+	}
+	if node.Path == nil {
+		return ""
+	}
+	path := node.Path.Value
+	return strings.TrimSuffix(path[strings.LastIndex(path, "/")+1:], ".fn")
+}
+
+// define marks a binding of the current scope as assigned.
+func (r *resolver) define(name string, line int) {
+	b, ok := r.scope.bindings[name]
+
+	if !ok {
+		return
+	}
+
+	if !b.defined && r.builtins[name] {
+		r.report(line, SHADOWED_BUILTIN, "%s shadows a builtin", name)
+	}
+
+	b.defined = true
+}
+
+// lookup resolves a use of name, marking the binding as used.
+func (r *resolver) lookup(name string, line int) *binding {
+	var pending *binding
+
+	for s := r.scope; s != nil; s = s.parent {
+		b, ok := s.bindings[name]
+
+		if !ok {
+			continue
+		}
+
+		// A name assigned later in the current scope is looked up in the outer scopes until then.
+		if s == r.scope && !b.defined {
+			pending = b
+			continue
+		}
+
+		b.used = true
+		return b
+	}
+
+	if r.builtins[name] || (r.tests > 0 && testHelpers[name]) {
+		return nil
+	}
+
+	if pending != nil {
+		pending.used = true
+		r.report(line, USE_BEFORE_DEFINITION, "%s is used before it is defined", name)
+		return nil
+	}
+
+	r.report(line, UNDEFINED, "%s is not defined", name)
+	return nil
+}
+
+// find returns the nearest binding of name from s outwards.
+func (s *scope) find(name string) *binding {
+	for ; s != nil; s = s.parent {
+		if b, ok := s.bindings[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+func (r *resolver) enter() {
+	r.scope = &scope{parent: r.scope, bindings: map[string]*binding{}}
+}
+
+// leave closes the current scope, reporting what was never used.
+func (r *resolver) leave() {
+	if !r.scope.global {
+		for _, b := range r.scope.bindings {
+			if b.used || strings.HasPrefix(b.name, "_") {
+				continue
+			}
+
+			switch b.kind {
+			case parameter:
+				r.report(b.line, UNUSED_PARAMETER, "parameter %s is never used", b.name)
+			case variable, function:
+				r.report(b.line, UNUSED_VARIABLE, "%s is assigned but never used", b.name)
+			}
+		}
+	}
+
+	r.scope = r.scope.parent
+}
+
+func (r *resolver) resolveFunction(params []*ast.Identifier, defaults map[string]ast.Expression, patterns map[string]ast.Expression, rest *ast.Identifier, body *ast.BlockStatement, implicit ...string) {
+	r.enter()
+	r.functions++
+
+	for _, name := range implicit {
+		r.scope.bindings[name] = &binding{name: name, kind: parameter, defined: true, used: true}
+	}
+
+	if rest != nil {
+		params = append(params, rest)
+	}
+
+	bound := []*ast.Identifier{}
+	for _, param := range params {
+		if pattern, ok := patterns[param.Value]; ok {
+			bound = append(bound, targetNames(pattern)...)
+		} else {
+			bound = append(bound, param)
+		}
+	}
+
+	for _, param := range bound {
+		if r.builtins[param.Value] {
+			r.report(param.Token.Line, SHADOWED_BUILTIN, "%s shadows a builtin", param.Value)
+		}
+		r.scope.bindings[param.Value] = &binding{name: param.Value, kind: parameter, line: param.Token.Line, defined: true}
+	}
+
+	for _, param := range params {
+		if value, ok := defaults[param.Value]; ok {
+			r.resolve(value)
+		}
+	}
+
+	if body != nil {
+		r.declare(body)
+		r.resolve(body)
+	}
+
+	r.functions--
+	r.leave()
+}
+
+// resolveClass resolves the class body in its own scope, where the constants are visible by name.
+func (r *resolver) resolveClass(node *ast.ClassStatement) {
+	for _, include := range node.Includes {
+		r.lookup(include.Value, include.Token.Line)
+	}
+
+	r.enter()
+
+	for _, constant := range node.Constants {
+		r.resolve(constant.Value)
+		// Constants are reachable through the class, they are never reported as unused.
+		r.scope.bindings[constant.Target.Value] = &binding{name: constant.Target.Value, kind: variable, line: constant.Target.Token.Line, defined: true, used: true}
+	}
+
+	for _, field := range node.Fields {
+		r.resolve(field.Value)
+	}
+
+	for _, method := range node.Statics {
+		if method != nil {
+			r.resolveFunction(method.Arguments, method.Defaults, method.Patterns, method.Rest, method.Body)
+		}
+	}
+
+	for _, method := range node.Methods {
+		if method != nil {
+			r.resolveFunction(method.Arguments, method.Defaults, method.Patterns, method.Rest, method.Body, "this")
+		}
+	}
+
+	r.leave()
+}
+
+func (r *resolver) resolve(node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FunctionStatement:
+			if n.Target != nil {
+				r.define(n.Target.Value, n.Token.Line)
+			}
+			r.resolveFunction(n.Arguments, n.Defaults, n.Patterns, n.Rest, n.Body)
+			return false
+		case *ast.Lambda:
+			r.resolveFunction(n.Arguments, n.Defaults, n.Patterns, n.Rest, n.Body)
+			return false
+		case *ast.ClassStatement:
+			if n.Target != nil {
+				r.define(n.Target.Value, n.Token.Line)
+			}
+			r.resolveClass(n)
+			return false
+		case *ast.TraitStatement:
+			if n.Target != nil {
+				r.define(n.Target.Value, n.Token.Line)
+			}
+			for _, method := range n.Methods {
+				if method != nil {
+					r.resolveFunction(method.Arguments, method.Defaults, method.Patterns, method.Rest, method.Body, "this")
+				}
+			}
+			return false
+		case *ast.TestStatement:
+			r.enter()
+			r.tests++
+			r.declare(n.Statements)
+			r.resolve(n.Statements)
+			r.tests--
+			r.leave()
+			return false
+		case *ast.ImportStatement:
+			r.define(importName(n), n.Token.Line)
+			return false
+		case *ast.ReturnStatement:
+			if r.functions == 0 {
+				r.report(n.Token.Line, RETURN_OUTSIDE_FUNCTION, "return outside of a function")
+			}
+		case *ast.LetStatement:
+			r.resolve(n.Value)
+			if n.Target != nil {
+				r.define(n.Target.Value, n.Target.Token.Line)
+			}
+			return false
+		case *ast.ConstStatement:
+			r.resolve(n.Value)
+			if n.Target != nil {
+				r.define(n.Target.Value, n.Target.Token.Line)
+			}
+			return false
+		case *ast.AssignmentExpression:
+			r.resolve(n.Value)
+			r.resolveTarget(n.Target)
+			return false
+		case *ast.MatchCase:
+			r.resolveCase(n)
+			return false
+		case *ast.CallExpression:
+			r.resolveCall(n.Function, n.Arguments, 0)
+			return false
+		case *ast.PipeExpression:
+			r.resolve(n.Left)
+			switch right := n.Right.(type) {
+			case *ast.CallExpression:
+				r.resolveCall(right.Function, right.Arguments, 1)
+			case *ast.Identifier:
+				r.resolveCall(right, nil, 1)
+			default:
+				r.resolve(right)
+			}
+			return false
+		case *ast.FieldExpression:
+			r.resolve(n.Target)
+			return false
+		case *ast.Identifier:
+			r.lookup(n.Value, n.Token.Line)
+			return false
+		}
+		return true
+	})
+}
+
+// resolveCase resolves a match case in its own scope, holding the names bound by the pattern.
+// resolveTarget defines the names assigned by target, fields and indexes are resolved as uses.
+func (r *resolver) resolveTarget(target ast.Expression) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		r.define(target.Value, target.Token.Line)
+	case *ast.Array:
+		for _, element := range target.Elements {
+			r.resolveTarget(element)
+		}
+	case *ast.Map:
+		for i, key := range target.Keys {
+			r.resolve(key)
+			r.resolveTarget(target.Values[i])
+		}
+	default:
+		r.resolve(target)
+	}
+}
+
+// targetNames lists the names assigned by a target, looking inside destructuring targets.
+func targetNames(target ast.Expression) []*ast.Identifier {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return []*ast.Identifier{target}
+	case *ast.Array:
+		names := []*ast.Identifier{}
+		for _, element := range target.Elements {
+			names = append(names, targetNames(element)...)
+		}
+		return names
+	case *ast.Map:
+		names := []*ast.Identifier{}
+		for _, value := range target.Values {
+			names = append(names, targetNames(value)...)
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+func (r *resolver) resolveCase(node *ast.MatchCase) {
+	r.enter()
+
+	r.bindPattern(node.Pattern)
+
+	if node.Guard != nil {
+		r.resolve(node.Guard)
+	}
+
+	if node.Body != nil {
+		r.declare(node.Body)
+		r.resolve(node.Body)
+	}
+
+	r.leave()
+}
+
+func (r *resolver) bindPattern(pattern ast.Expression) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			r.scope.bindings[pattern.Value] = &binding{name: pattern.Value, kind: variable, line: pattern.Token.Line, defined: true}
+		}
+	case *ast.Array:
+		for _, element := range pattern.Elements {
+			r.bindPattern(element)
+		}
+	case *ast.Map:
+		for i, key := range pattern.Keys {
+			r.resolve(key)
+			r.bindPattern(pattern.Values[i])
+		}
+	case *ast.CallExpression:
+		r.resolve(pattern.Function)
+		for _, arg := range pattern.Arguments {
+			if keyword, ok := arg.(*ast.KeywordArgument); ok {
+				arg = keyword.Value
+			}
+			r.bindPattern(arg)
+		}
+	default:
+		r.resolve(pattern)
+	}
+}
+
+// resolveCall checks the arguments given to functions and classes, piped counts the values passed by `|>`.
+func (r *resolver) resolveCall(callee ast.Expression, arguments []ast.Expression, piped int) {
+	for _, arg := range arguments {
+		r.resolve(arg)
+	}
+
+	ident, ok := callee.(*ast.Identifier)
+
+	if !ok {
+		r.resolve(callee)
+		return
+	}
+
+	b := r.lookup(ident.Value, ident.Token.Line)
+
+	if b == nil || b.arity == nil || (b.kind != function && b.kind != class) {
+		return
+	}
+
+	for _, arg := range arguments {
+		if _, ok := arg.(*ast.KeywordArgument); ok {
+			return
+		}
+	}
+
+	if count := len(arguments) + piped; !b.arity.allows(count) {
+		r.report(ident.Token.Line, ARGUMENT_COUNT, "%s takes %s, got %d", ident.Value, b.arity, count)
+	}
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,150 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/parser"
+)
+
+func TestCheck(t *testing.T) {
+	tt := []struct {
+		name   string
+		input  string
+		issues []string
+	}{
+		{
+			"clean",
+			"fn add(a, b)\n return a + b\nend\nprint(add(1, 2))",
+			nil,
+		},
+		{
+			"undefined",
+			"print(missing)",
+			[]string{"test.fn:1: missing is not defined (undefined)"},
+		},
+		{
+			"use before definition",
+			"print(x)\nx = 1\nprint(x)",
+			[]string{"test.fn:1: x is used before it is defined (use-before-definition)"},
+		},
+		{
+			"function used by another defined later",
+			"fn a()\n return b()\nend\nfn b()\n return 1\nend\na()",
+			nil,
+		},
+		{
+			"unused local and parameter",
+			"fn f(a, b)\n c = 1\n return a\nend\nf(1, 2)",
+			[]string{
+				"test.fn:1: parameter b is never used (unused-parameter)",
+				"test.fn:2: c is assigned but never used (unused-variable)",
+			},
+		},
+		{
+			"argument count",
+			"fn add(a, b)\n return a + b\nend\nadd(1)\nclass Point\n fn init(x, y)\n this.x = x\n this.y = y\n end\nend\nPoint(1, 2, 3)",
+			[]string{
+				"test.fn:4: add takes 2 arguments, got 1 (argument-count)",
+				"test.fn:11: Point takes 2 arguments, got 3 (argument-count)",
+			},
+		},
+		{
+			"closure updates the enclosing variable",
+			"fn counter()\n count = 0\n return fn()\n  count = count + 1\n end\nend\ncounter()",
+			nil,
+		},
+		{
+			"unused let",
+			"total = 0\nfn f()\n let total = 1\nend\nf()",
+			[]string{"test.fn:3: total is assigned but never used (unused-variable)"},
+		},
+		{
+			"defaults, rest and keyword arguments",
+			"fn f(a, b = a, ...xs)\n return b + len(xs)\nend\nf(1)\nf(b: 2, a: 1)\nf(1, 2, 3, 4)",
+			nil,
+		},
+		{
+			"defaults and rest bound the argument count",
+			"fn g(a, b = 1)\n return a + b\nend\nfn h(a, ...xs)\n return a + len(xs)\nend\ng()\ng(1, 2, 3)\nh()\nh(1, 2, 3)",
+			[]string{
+				"test.fn:7: g takes 1 to 2 arguments, got 0 (argument-count)",
+				"test.fn:8: g takes 1 to 2 arguments, got 3 (argument-count)",
+				"test.fn:9: h takes at least 1 argument, got 0 (argument-count)",
+			},
+		},
+		{
+			"class constants, fields and statics",
+			"class P\n const ZERO = 0\n x = ZERO\n static fn origin()\n  return this\n end\n fn get()\n  return ZERO\n end\nend\nP.origin()",
+			[]string{"test.fn:5: this is not defined (undefined)"},
+		},
+		{
+			"traits",
+			"trait Named\n fn name()\n  return this.label\n end\nend\nclass Dog\n include Named\n include Missing\nend\nDog()",
+			[]string{"test.fn:8: Missing is not defined (undefined)"},
+		},
+		{
+			"match patterns bind names",
+			"class P\n fn init(x)\n  this.x = x\n end\nend\nv = P(1)\nmatch v\ncase P(x) if x > 0 then\n x\ncase [a, _] then\n b\nend",
+			[]string{"test.fn:10: a is assigned but never used (unused-variable)", "test.fn:11: b is not defined (undefined)"},
+		},
+		{
+			"destructuring binds names",
+			"fn swap([a, b], {\"n\" = n})\n x, y = b, a\n return x\nend\nswap([1, 2], {\"n\" = 3})",
+			[]string{"test.fn:1: parameter n is never used (unused-parameter)", "test.fn:2: y is assigned but never used (unused-variable)"},
+		},
+		{
+			"compound assignment reads the target",
+			"count = 0\ncount += 1\ntotal += count",
+			[]string{"test.fn:3: total is not defined (undefined)"},
+		},
+		{
+			"pipelines pass an argument",
+			"fn add(a, b)\n return a + b\nend\n1 |> add(2)\n1 |> add(2, 3)\n1 |> add",
+			[]string{"test.fn:5: add takes 2 arguments, got 3 (argument-count)", "test.fn:6: add takes 2 arguments, got 1 (argument-count)"},
+		},
+		{
+			"return outside function",
+			"return 1",
+			[]string{"test.fn:1: return outside of a function (return-outside-function)"},
+		},
+		{
+			"shadowed builtin",
+			"fn count(xs)\n len = len(xs)\n return len\nend\ncount([1])",
+			[]string{"test.fn:2: len shadows a builtin (shadowed-builtin)"},
+		},
+		{
+			"stale value in test",
+			"test \"first\"\n result = 1\n assert(result, 1)\nend\ntest \"second\"\n assert(result, 1)\nend",
+			[]string{"test.fn:6: result is not defined (undefined)"},
+		},
+		{
+			"methods see this",
+			"class Counter\n fn inc()\n  this.count = this.count + 1\n end\nend\nCounter().inc()",
+			nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parser.New(lexer.New(tc.input))
+			program := p.Parse()
+
+			if len(p.Errors()) > 0 {
+				t.Fatalf("Parser errors: %v", p.Errors())
+			}
+
+			issues := Check("test.fn", program)
+
+			if len(issues) != len(tc.issues) {
+				t.Fatalf("Expected issues %v, got %v", tc.issues, issues)
+			}
+
+			for i, issue := range issues {
+				if issue.String() != tc.issues[i] {
+					t.Errorf("Expected issue %q, got %q", tc.issues[i], issue.String())
+				}
+			}
+		})
+	}
+}
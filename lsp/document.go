@@ -0,0 +1,552 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/format"
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/parser"
+	"github.com/pspiagicw/fener/token"
+)
+
+// scope covers the lines of a function body, names defined in it are only visible there.
+type scope struct {
+	start  int
+	end    int
+	parent *scope
+	names  map[string]bool
+}
+
+func (s *scope) binds(name string) bool {
+	for ; s != nil; s = s.parent {
+		if s.names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scope) contains(line int) bool {
+	return s.start <= line && line <= s.end
+}
+
+type definition struct {
+	name   string
+	kind   SymbolKind
+	line   int
+	scope  *scope
+	detail string
+	// class is set for methods, they are only reachable with a `.`.
+	class string
+}
+
+// document is an open file along with everything derived from parsing it.
+type document struct {
+	uri   string
+	text  string
+	lines []string
+
+	program     *ast.Program
+	diagnostics []parser.Diagnostic
+	tokens      []*token.Token
+
+	global      *scope
+	definitions []*definition
+}
+
+func newDocument(uri string, text string) *document {
+	d := &document{
+		uri:   uri,
+		text:  text,
+		lines: strings.Split(text, "\n"),
+	}
+
+	l := lexer.New(text)
+	for t := l.Next(); t.Type != token.EOF; t = l.Next() {
+		d.tokens = append(d.tokens, t)
+	}
+
+	p := parser.New(lexer.New(text))
+	d.program = p.Parse()
+	d.diagnostics = p.Diagnostics()
+
+	d.global = &scope{start: 0, end: len(d.lines), names: map[string]bool{}}
+	d.collect(d.program, d.global)
+
+	return d
+}
+
+func (d *document) define(def *definition) {
+	if def.class == "" {
+		if def.scope.names[def.name] {
+			return
+		}
+		def.scope.names[def.name] = true
+	}
+
+	d.definitions = append(d.definitions, def)
+}
+
+// collect records the definitions inside node.
+func (d *document) collect(node ast.Node, s *scope) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FunctionStatement:
+			if n.Target == nil {
+				return false
+			}
+			d.define(&definition{name: n.Target.Value, kind: symbolFunction, line: n.Token.Line, scope: s, detail: signature("fn "+n.Target.Value, n)})
+			d.collectFunction(n.Token, n.Arguments, n.Patterns, n.Rest, n.Body, s)
+			return false
+		case *ast.Lambda:
+			d.collectFunction(n.Token, n.Arguments, n.Patterns, n.Rest, n.Body, s)
+			return false
+		case *ast.ClassStatement:
+			if n.Target == nil {
+				return false
+			}
+			d.define(&definition{name: n.Target.Value, kind: symbolClass, line: n.Token.Line, scope: s, detail: classSignature(n)})
+			for _, constant := range n.Constants {
+				d.define(&definition{name: constant.Target.Value, kind: symbolConstant, line: constant.Target.Token.Line, scope: s, class: n.Target.Value, detail: constant.String()})
+			}
+			for _, method := range n.Statics {
+				if method == nil || method.Target == nil {
+					continue
+				}
+				d.define(&definition{name: method.Target.Value, kind: symbolMethod, line: method.Token.Line, scope: s, class: n.Target.Value, detail: signature("static fn "+n.Target.Value+"."+method.Target.Value, method)})
+				d.collectFunction(method.Token, method.Arguments, method.Patterns, method.Rest, method.Body, s)
+			}
+			for _, method := range n.Methods {
+				if method == nil || method.Target == nil {
+					continue
+				}
+				d.define(&definition{name: method.Target.Value, kind: symbolMethod, line: method.Token.Line, scope: s, class: n.Target.Value, detail: signature("fn "+n.Target.Value+"."+method.Target.Value, method)})
+				d.collectFunction(method.Token, method.Arguments, method.Patterns, method.Rest, method.Body, s)
+			}
+			return false
+		case *ast.TraitStatement:
+			if n.Target == nil {
+				return false
+			}
+			d.define(&definition{name: n.Target.Value, kind: symbolInterface, line: n.Token.Line, scope: s, detail: "trait " + n.Target.Value})
+			for _, method := range n.Methods {
+				if method == nil || method.Target == nil {
+					continue
+				}
+				d.define(&definition{name: method.Target.Value, kind: symbolMethod, line: method.Token.Line, scope: s, class: n.Target.Value, detail: signature("fn "+n.Target.Value+"."+method.Target.Value, method)})
+				d.collectFunction(method.Token, method.Arguments, method.Patterns, method.Rest, method.Body, s)
+			}
+			return false
+		case *ast.TestStatement:
+			inner := d.enclosed(n.Token, s)
+			d.collect(n.Statements, inner)
+			return false
+		case *ast.LetStatement:
+			if n.Target != nil && n.Value != nil {
+				d.define(&definition{name: n.Target.Value, kind: symbolVariable, line: n.Target.Token.Line, scope: s, detail: n.String()})
+			}
+		case *ast.ConstStatement:
+			if n.Target != nil && n.Value != nil {
+				d.define(&definition{name: n.Target.Value, kind: symbolConstant, line: n.Target.Token.Line, scope: s, detail: n.String()})
+			}
+		case *ast.AssignmentExpression:
+			// Assigning a name bound in an enclosing scope updates it instead of defining a local.
+			if n.Value == nil {
+				break
+			}
+			for _, ident := range assigned(n.Target) {
+				if !s.parent.binds(ident.Value) {
+					d.define(&definition{name: ident.Value, kind: symbolVariable, line: ident.Token.Line, scope: s, detail: fmt.Sprintf("%s = %s", n.Target.String(), n.Value.String())})
+				}
+			}
+		case *ast.ImportStatement:
+			if n.Path == nil {
+				return false
+			}
+			name := strings.TrimSuffix(n.Path.Value[strings.LastIndex(n.Path.Value, "/")+1:], ".fn")
+			if n.Alias != nil {
+				name = n.Alias.Value
+			}
+			d.define(&definition{name: name, kind: symbolModule, line: n.Token.Line, scope: s, detail: n.String()})
+			return false
+		}
+		return true
+	})
+}
+
+func (d *document) collectFunction(start *token.Token, args []*ast.Identifier, patterns map[string]ast.Expression, rest *ast.Identifier, body *ast.BlockStatement, s *scope) {
+	inner := d.enclosed(start, s)
+
+	if rest != nil {
+		args = append(args, rest)
+	}
+
+	params := []*ast.Identifier{}
+	for _, arg := range args {
+		if pattern, ok := patterns[arg.Value]; ok {
+			params = append(params, assigned(pattern)...)
+		} else {
+			params = append(params, arg)
+		}
+	}
+
+	for _, arg := range params {
+		d.define(&definition{name: arg.Value, kind: symbolVariable, line: arg.Token.Line, scope: inner, detail: "parameter " + arg.Value})
+	}
+
+	if body != nil {
+		d.collect(body, inner)
+	}
+}
+
+// assigned lists the names bound by an assignment target, looking inside destructuring targets.
+func assigned(target ast.Expression) []*ast.Identifier {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return []*ast.Identifier{target}
+	case *ast.Array:
+		names := []*ast.Identifier{}
+		for _, element := range target.Elements {
+			names = append(names, assigned(element)...)
+		}
+		return names
+	case *ast.Map:
+		names := []*ast.Identifier{}
+		for _, value := range target.Values {
+			names = append(names, assigned(value)...)
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+func (d *document) enclosed(start *token.Token, s *scope) *scope {
+	return &scope{start: start.Line, end: d.blockEnd(start), parent: s, names: map[string]bool{}}
+}
+
+// blockEnd finds the line of the `end` closing the block opened by start.
+func (d *document) blockEnd(start *token.Token) int {
+	depth := 0
+
+	for _, t := range d.tokens {
+		if depth == 0 && (t.Line < start.Line || t.Type != start.Type) {
+			continue
+		}
+
+		switch t.Type {
+		case token.IF, token.FUNCTION, token.WHILE, token.CLASS, token.TEST, token.MATCH, token.TRAIT:
+			depth++
+		case token.END:
+			depth--
+			if depth == 0 {
+				return t.Line
+			}
+		}
+	}
+
+	return len(d.lines) - 1
+}
+
+func signature(name string, fn *ast.FunctionStatement) string {
+	return fmt.Sprintf("%s(%s)", name, ast.Parameters(fn.Arguments, fn.Defaults, fn.Rest))
+}
+
+func classSignature(class *ast.ClassStatement) string {
+	for _, method := range class.Methods {
+		if method != nil && method.Target != nil && method.Target.Value == "init" {
+			return signature("class "+class.Target.Value, method)
+		}
+	}
+	return "class " + class.Target.Value
+}
+
+// nameRange locates name on line, falling back to the start of the line.
+func (d *document) nameRange(line int, name string) Range {
+	character := 0
+
+	if line < len(d.lines) {
+		if i := findWord(d.lines[line], name); i >= 0 {
+			character = i
+		}
+	}
+
+	return Range{
+		Start: Position{Line: line, Character: character},
+		End:   Position{Line: line, Character: character + len(name)},
+	}
+}
+
+func (d *document) lineRange(start int, end int) Range {
+	length := 0
+	if end < len(d.lines) {
+		length = len(d.lines[end])
+	}
+
+	return Range{
+		Start: Position{Line: start},
+		End:   Position{Line: end, Character: length},
+	}
+}
+
+func findWord(line string, word string) int {
+	for offset := 0; offset < len(line); {
+		i := strings.Index(line[offset:], word)
+		if i < 0 {
+			return -1
+		}
+		i += offset
+
+		before := i == 0 || !isIdentifier(rune(line[i-1]))
+		after := i+len(word) == len(line) || !isIdentifier(rune(line[i+len(word)]))
+
+		if before && after {
+			return i
+		}
+
+		offset = i + 1
+	}
+
+	return -1
+}
+
+func isIdentifier(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordAt returns the identifier under the position and whether it follows a `.`.
+func (d *document) wordAt(pos Position) (string, bool) {
+	if pos.Line >= len(d.lines) {
+		return "", false
+	}
+
+	line := d.lines[pos.Line]
+
+	start := min(pos.Character, len(line))
+	for start > 0 && isIdentifier(rune(line[start-1])) {
+		start--
+	}
+
+	end := min(pos.Character, len(line))
+	for end < len(line) && isIdentifier(rune(line[end])) {
+		end++
+	}
+
+	return line[start:end], start > 0 && line[start-1] == '.'
+}
+
+// resolve finds the definition of name visible on line, preferring the innermost scope.
+func (d *document) resolve(name string, member bool, line int) *definition {
+	var found *definition
+
+	for _, def := range d.definitions {
+		if def.name != name || (def.class != "") != member {
+			continue
+		}
+
+		if member {
+			return def
+		}
+
+		if !def.scope.contains(line) {
+			continue
+		}
+
+		if found == nil || def.scope.start > found.scope.start {
+			found = def
+		}
+	}
+
+	return found
+}
+
+func (d *document) Diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, diagnostic := range d.diagnostics {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.lineRange(diagnostic.Line, diagnostic.Line),
+			Severity: severityError,
+			Source:   "fener",
+			Message:  diagnostic.Message,
+		})
+	}
+
+	return diagnostics
+}
+
+func (d *document) Symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+
+	for _, stmt := range d.program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.FunctionStatement:
+			if stmt == nil || stmt.Target == nil {
+				continue
+			}
+			symbols = append(symbols, d.symbol(stmt.Target.Value, symbolFunction, stmt.Token, signature("fn "+stmt.Target.Value, stmt)))
+		case *ast.ClassStatement:
+			if stmt == nil || stmt.Target == nil {
+				continue
+			}
+			symbol := d.symbol(stmt.Target.Value, symbolClass, stmt.Token, classSignature(stmt))
+			for _, constant := range stmt.Constants {
+				symbol.Children = append(symbol.Children, d.symbol(constant.Target.Value, symbolConstant, constant.Token, constant.String()))
+			}
+			for _, method := range stmt.Statics {
+				if method == nil || method.Target == nil {
+					continue
+				}
+				symbol.Children = append(symbol.Children, d.symbol(method.Target.Value, symbolMethod, method.Token, signature("static fn "+method.Target.Value, method)))
+			}
+			for _, method := range stmt.Methods {
+				if method == nil || method.Target == nil {
+					continue
+				}
+				symbol.Children = append(symbol.Children, d.symbol(method.Target.Value, symbolMethod, method.Token, signature("fn "+method.Target.Value, method)))
+			}
+			symbols = append(symbols, symbol)
+		case *ast.TraitStatement:
+			if stmt == nil || stmt.Target == nil {
+				continue
+			}
+			symbol := d.symbol(stmt.Target.Value, symbolInterface, stmt.Token, "trait "+stmt.Target.Value)
+			for _, method := range stmt.Methods {
+				if method == nil || method.Target == nil {
+					continue
+				}
+				symbol.Children = append(symbol.Children, d.symbol(method.Target.Value, symbolMethod, method.Token, signature("fn "+method.Target.Value, method)))
+			}
+			symbols = append(symbols, symbol)
+		case *ast.TestStatement:
+			if stmt == nil || stmt.Target == nil {
+				continue
+			}
+			symbols = append(symbols, d.symbol(stmt.Target.Value, symbolTest, stmt.Token, "test"))
+		}
+	}
+
+	return symbols
+}
+
+func (d *document) symbol(name string, kind SymbolKind, start *token.Token, detail string) DocumentSymbol {
+	return DocumentSymbol{
+		Name:           name,
+		Detail:         detail,
+		Kind:           kind,
+		Range:          d.lineRange(start.Line, d.blockEnd(start)),
+		SelectionRange: d.nameRange(start.Line, name),
+	}
+}
+
+func (d *document) Definition(pos Position) *Location {
+	name, member := d.wordAt(pos)
+
+	def := d.resolve(name, member, pos.Line)
+
+	if name == "" || def == nil {
+		return nil
+	}
+
+	return &Location{URI: d.uri, Range: d.nameRange(def.line, def.name)}
+}
+
+func (d *document) Hover(pos Position) *Hover {
+	name, member := d.wordAt(pos)
+
+	if name == "" {
+		return nil
+	}
+
+	detail := ""
+
+	if def := d.resolve(name, member, pos.Line); def != nil {
+		detail = def.detail
+	} else if !member && isBuiltin(name) {
+		detail = "builtin " + name
+	} else {
+		return nil
+	}
+
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: "```fener\n" + detail + "\n```"}}
+}
+
+func isBuiltin(name string) bool {
+	for _, builtin := range object.BuiltinNames() {
+		if builtin == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *document) Completion(pos Position) []CompletionItem {
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+
+	add := func(item CompletionItem) {
+		if !seen[item.Label] {
+			seen[item.Label] = true
+			items = append(items, item)
+		}
+	}
+
+	for _, def := range d.definitions {
+		if def.class == "" && def.scope.contains(pos.Line) {
+			add(CompletionItem{Label: def.name, Kind: completionKind(def.kind), Detail: def.detail})
+		}
+	}
+
+	for _, builtin := range object.BuiltinNames() {
+		add(CompletionItem{Label: builtin, Kind: completionFunction, Detail: "builtin"})
+	}
+
+	for _, keyword := range lexer.Keywords() {
+		add(CompletionItem{Label: keyword, Kind: completionKeyword})
+	}
+
+	return items
+}
+
+func completionKind(kind SymbolKind) CompletionItemKind {
+	switch kind {
+	case symbolFunction:
+		return completionFunction
+	case symbolClass:
+		return completionClass
+	case symbolInterface:
+		return completionInterface
+	case symbolModule:
+		return completionModule
+	case symbolConstant:
+		return completionConstant
+	default:
+		return completionVariable
+	}
+}
+
+// Format returns an edit replacing the whole document, documents with errors are left alone.
+// The formatter prints the tree, which has no comments, so documents with comments are left alone too.
+func (d *document) Format() []TextEdit {
+	if len(d.diagnostics) > 0 {
+		return nil
+	}
+
+	for _, t := range d.tokens {
+		if t.Type == token.COMMENT {
+			return []TextEdit{}
+		}
+	}
+
+	formatted := format.Format(d.program)
+
+	if formatted == d.text {
+		return []TextEdit{}
+	}
+
+	return []TextEdit{{Range: d.lineRange(0, len(d.lines)-1), NewText: formatted}}
+}
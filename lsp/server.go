@@ -0,0 +1,301 @@
+// Package lsp implements a language server for fener over stdin and stdout.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pspiagicw/fener/argparse"
+	"github.com/pspiagicw/fener/wire"
+	"github.com/pspiagicw/goreland"
+)
+
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"initialized":                 ignore,
+	"shutdown":                    (*Server).shutdown,
+	"textDocument/didOpen":        (*Server).didOpen,
+	"textDocument/didChange":      (*Server).didChange,
+	"textDocument/didClose":       (*Server).didClose,
+	"textDocument/didSave":        ignore,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/formatting":     (*Server).formatting,
+	"textDocument/completion":     (*Server).completion,
+}
+
+type Server struct {
+	in      *bufio.Reader
+	out     io.Writer
+	version string
+
+	documents map[string]*document
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:        bufio.NewReader(in),
+		out:       out,
+		documents: make(map[string]*document),
+	}
+}
+
+func Entry(opts *argparse.Opts) {
+	s := NewServer(os.Stdin, os.Stdout)
+	s.version = opts.Version
+
+	if err := s.Run(); err != nil {
+		goreland.LogFatal("Language server failed: %v", err)
+	}
+}
+
+// Run serves requests until the client sends exit or closes the connection.
+func (s *Server) Run() error {
+	for {
+		body, err := wire.Read(s.in)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msg := &message{}
+
+		if err := json.Unmarshal(body, msg); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: parseErrorCode, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		if err := s.dispatch(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) dispatch(msg *message) error {
+	h, ok := handlers[msg.Method]
+
+	if !ok {
+		// Unknown notifications are dropped, unknown requests get an error.
+		if msg.ID == nil {
+			return nil
+		}
+		return s.reply(msg.ID, nil, &responseError{Code: methodNotFoundCode, Message: fmt.Sprintf("method %s not supported", msg.Method)})
+	}
+
+	result, respErr := s.handle(h, msg.Params)
+
+	if msg.ID == nil {
+		return nil
+	}
+
+	return s.reply(msg.ID, result, respErr)
+}
+
+// handle runs h, turning a panic into an error so one bad document can't take the server down.
+func (s *Server) handle(h handler, params json.RawMessage) (result interface{}, respErr *responseError) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, respErr = nil, &responseError{Code: internalErrorCode, Message: fmt.Sprintf("internal error: %v", r)}
+		}
+	}()
+
+	result, err := h(s, params)
+
+	if err != nil {
+		return nil, &responseError{Code: invalidParamsCode, Message: err.Error()}
+	}
+
+	return result, nil
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, respErr *responseError) error {
+	return s.send(&response{JSONRPC: "2.0", ID: id, Result: result, Error: respErr})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.send(&notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) send(v interface{}) error {
+	body, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	return wire.Write(s.out, body)
+}
+
+func ignore(s *Server, params json.RawMessage) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           syncFull,
+			DocumentSymbolProvider:     true,
+			DefinitionProvider:         true,
+			HoverProvider:              true,
+			DocumentFormattingProvider: true,
+			CompletionProvider:         &CompletionOptions{},
+		},
+		ServerInfo: ServerInfo{Name: "fener", Version: s.version},
+	}, nil
+}
+
+func (s *Server) shutdown(params json.RawMessage) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *Server) open(uri string, text string) error {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: uri, Diagnostics: doc.Diagnostics()})
+}
+
+func (s *Server) didOpen(params json.RawMessage) (interface{}, error) {
+	p := &DidOpenTextDocumentParams{}
+
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	return nil, s.open(p.TextDocument.URI, p.TextDocument.Text)
+}
+
+func (s *Server) didChange(params json.RawMessage) (interface{}, error) {
+	p := &DidChangeTextDocumentParams{}
+
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	if len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+
+	// Only full syncs are advertised, so the last change holds the whole text.
+	return nil, s.open(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+}
+
+func (s *Server) didClose(params json.RawMessage) (interface{}, error) {
+	p := &DidCloseTextDocumentParams{}
+
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	delete(s.documents, p.TextDocument.URI)
+
+	return nil, s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.documents[uri]
+
+	if !ok {
+		return nil, fmt.Errorf("document %s is not open", uri)
+	}
+
+	return doc, nil
+}
+
+func (s *Server) position(params json.RawMessage) (*document, Position, error) {
+	p := &TextDocumentPositionParams{}
+
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, Position{}, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	return doc, p.Position, err
+}
+
+func (s *Server) documentSymbol(params json.RawMessage) (interface{}, error) {
+	p := &DocumentParams{}
+
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Symbols(), nil
+}
+
+func (s *Server) definition(params json.RawMessage) (interface{}, error) {
+	doc, pos, err := s.position(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if location := doc.Definition(pos); location != nil {
+		return location, nil
+	}
+
+	return nil, nil
+}
+
+func (s *Server) hover(params json.RawMessage) (interface{}, error) {
+	doc, pos, err := s.position(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if hover := doc.Hover(pos); hover != nil {
+		return hover, nil
+	}
+
+	return nil, nil
+}
+
+func (s *Server) formatting(params json.RawMessage) (interface{}, error) {
+	p := &DocumentParams{}
+
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Format(), nil
+}
+
+func (s *Server) completion(params json.RawMessage) (interface{}, error) {
+	doc, pos, err := s.position(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Completion(pos), nil
+}
@@ -0,0 +1,411 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pspiagicw/fener/wire"
+)
+
+const uri = "file:///tmp/shapes.fn"
+
+const source = `import "math"
+
+fn area(width, height)
+ total = width * height
+ return total
+end
+
+class Point
+ fn init(x, y)
+  this.x = x
+ end
+ fn norm()
+  return math.abs(this.x)
+ end
+end
+
+test "area"
+ print(area(2, 3))
+end
+`
+
+type received struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// client talks to a server running in the same process.
+type client struct {
+	t        *testing.T
+	out      io.Writer
+	id       int
+	messages chan *received
+	notes    []*received
+	closed   chan error
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{t: t, out: clientOut, messages: make(chan *received, 64), closed: make(chan error, 1)}
+
+	go func() {
+		err := NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+		c.closed <- err
+	}()
+
+	// Read everything the server sends, so it never blocks on writing.
+	go func() {
+		in := bufio.NewReader(clientIn)
+		defer close(c.messages)
+
+		for {
+			body, err := wire.Read(in)
+			if err != nil {
+				return
+			}
+
+			msg := &received{}
+			if err := json.Unmarshal(body, msg); err != nil {
+				return
+			}
+
+			c.messages <- msg
+		}
+	}()
+
+	return c
+}
+
+func (c *client) send(v interface{}) {
+	body, _ := json.Marshal(v)
+
+	if err := wire.Write(c.out, body); err != nil {
+		c.t.Fatalf("Error writing message: %v", err)
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.send(&notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// request returns the result of a request, notifications sent meanwhile are kept.
+func (c *client) request(method string, params interface{}, result interface{}) {
+	c.id++
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+
+	for msg := range c.messages {
+		if msg.ID == nil {
+			c.notes = append(c.notes, msg)
+			continue
+		}
+
+		if msg.Error != nil {
+			c.t.Fatalf("%s failed: %s", method, msg.Error.Message)
+		}
+
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatalf("Error decoding result of %s: %v", method, err)
+		}
+
+		return
+	}
+
+	c.t.Fatalf("Server closed the connection during %s", method)
+}
+
+func (c *client) open(text string) {
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, Text: text}})
+}
+
+func (c *client) diagnostics() []Diagnostic {
+	// A request makes sure every earlier notification has arrived.
+	c.request("shutdown", nil, &struct{}{})
+
+	note := c.notes[len(c.notes)-1]
+	params := &PublishDiagnosticsParams{}
+	json.Unmarshal(note.Params, params)
+
+	return params.Diagnostics
+}
+
+func (c *client) exit() {
+	c.notify("exit", nil)
+
+	if err := <-c.closed; err != nil {
+		c.t.Fatalf("Server failed: %v", err)
+	}
+}
+
+func at(line int, character int) *TextDocumentPositionParams {
+	return &TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Line: line, Character: character}}
+}
+
+func TestInitialize(t *testing.T) {
+	c := newClient(t)
+
+	result := &InitializeResult{}
+	c.request("initialize", map[string]interface{}{}, result)
+
+	if !result.Capabilities.HoverProvider || result.Capabilities.TextDocumentSync != syncFull {
+		t.Errorf("Unexpected capabilities %+v", result.Capabilities)
+	}
+
+	c.exit()
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := newClient(t)
+
+	c.open("a = 1\nfn (x)\nend\n")
+
+	diagnostics := c.diagnostics()
+
+	if len(diagnostics) == 0 {
+		t.Fatalf("Expected diagnostics for invalid document")
+	}
+
+	if diagnostics[0].Range.Start.Line != 1 || diagnostics[0].Severity != severityError {
+		t.Errorf("Expected an error on line 1, got %+v", diagnostics[0])
+	}
+
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: source}},
+	})
+
+	if diagnostics := c.diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diagnostics)
+	}
+
+	c.exit()
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	c := newClient(t)
+	c.open(source)
+
+	symbols := []DocumentSymbol{}
+	c.request("textDocument/documentSymbol", &DocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+
+	type summary struct {
+		Name     string
+		Kind     SymbolKind
+		Start    int
+		End      int
+		Children int
+	}
+
+	got := []summary{}
+	for _, s := range symbols {
+		got = append(got, summary{s.Name, s.Kind, s.Range.Start.Line, s.Range.End.Line, len(s.Children)})
+	}
+
+	expected := []summary{
+		{"area", symbolFunction, 2, 5, 0},
+		{"Point", symbolClass, 7, 14, 2},
+		{"area", symbolTest, 16, 18, 0},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected symbols %+v, got %+v", expected, got)
+	}
+
+	c.exit()
+}
+
+func TestBlockRanges(t *testing.T) {
+	tt := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"match", "fn sign(n)\n match n\n case 0 then 0\n case _ then 1\n end\nend\n", 5},
+		{"trait", "trait Describe\n fn describe()\n  return 1\n end\nend\n", 4},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newClient(t)
+			c.open(tc.text)
+
+			symbols := []DocumentSymbol{}
+			c.request("textDocument/documentSymbol", &DocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+
+			if len(symbols) != 1 || symbols[0].Range.End.Line != tc.expected {
+				t.Errorf("Expected a symbol ending on line %d, got %+v", tc.expected, symbols)
+			}
+
+			c.exit()
+		})
+	}
+}
+
+func TestIncompleteDocuments(t *testing.T) {
+	tt := []string{"fn", "fn f(", "test", "static fn", "fn(x) end", "class", "class Point\n fn"}
+
+	for _, text := range tt {
+		t.Run(text, func(t *testing.T) {
+			c := newClient(t)
+			c.open(text)
+
+			symbols := []DocumentSymbol{}
+			c.request("textDocument/documentSymbol", &DocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+
+			var hover *Hover
+			c.request("textDocument/hover", at(0, 1), &hover)
+
+			c.exit()
+		})
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	s := NewServer(strings.NewReader(""), io.Discard)
+
+	crash := func(s *Server, params json.RawMessage) (interface{}, error) {
+		var d *document
+		return d.Symbols(), nil
+	}
+
+	_, respErr := s.handle(crash, nil)
+
+	if respErr == nil || respErr.Code != internalErrorCode {
+		t.Errorf("Expected an internal error, got %+v", respErr)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	tt := []struct {
+		name     string
+		position *TextDocumentPositionParams
+		expected *Range
+	}{
+		{"function", at(17, 8), &Range{Start: Position{2, 3}, End: Position{2, 7}}},
+		{"parameter", at(3, 10), &Range{Start: Position{2, 8}, End: Position{2, 13}}},
+		{"local", at(4, 10), &Range{Start: Position{3, 1}, End: Position{3, 6}}},
+		{"module", at(12, 10), &Range{Start: Position{0, 8}, End: Position{0, 12}}},
+		{"unknown", at(17, 0), nil},
+	}
+
+	c := newClient(t)
+	c.open(source)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var location *Location
+			c.request("textDocument/definition", tc.position, &location)
+
+			if tc.expected == nil {
+				if location != nil {
+					t.Errorf("Expected no definition, got %+v", location)
+				}
+				return
+			}
+
+			if location == nil || location.Range != *tc.expected {
+				t.Errorf("Expected definition at %+v, got %+v", tc.expected, location)
+			}
+		})
+	}
+
+	c.exit()
+}
+
+func TestHover(t *testing.T) {
+	tt := []struct {
+		position *TextDocumentPositionParams
+		expected string
+	}{
+		{at(17, 8), "fn area(width, height)"},
+		{at(17, 2), "builtin print"},
+		{at(7, 7), "class Point(x, y)"},
+		{at(12, 22), ""},
+	}
+
+	c := newClient(t)
+	c.open(source)
+
+	for _, tc := range tt {
+		var hover *Hover
+		c.request("textDocument/hover", tc.position, &hover)
+
+		if tc.expected == "" {
+			if hover != nil {
+				t.Errorf("Expected no hover, got %+v", hover)
+			}
+			continue
+		}
+
+		if hover == nil || !strings.Contains(hover.Contents.Value, tc.expected) {
+			t.Errorf("Expected hover containing %q, got %+v", tc.expected, hover)
+		}
+	}
+
+	c.exit()
+}
+
+func TestFormatting(t *testing.T) {
+	c := newClient(t)
+	c.open("a = 1 + 2\n")
+
+	edits := []TextEdit{}
+	c.request("textDocument/formatting", &DocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &edits)
+
+	if len(edits) != 1 || edits[0].NewText != "a = (1 + 2)\n" {
+		t.Errorf("Unexpected edits %+v", edits)
+	}
+
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: ";; sum\na = 1 + 2\n"}},
+	})
+
+	c.request("textDocument/formatting", &DocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &edits)
+
+	if len(edits) != 0 {
+		t.Errorf("Expected no edits for a commented document, got %+v", edits)
+	}
+
+	c.exit()
+}
+
+func TestCompletion(t *testing.T) {
+	c := newClient(t)
+	c.open(source)
+
+	labels := func(position *TextDocumentPositionParams) map[string]bool {
+		items := []CompletionItem{}
+		c.request("textDocument/completion", position, &items)
+
+		found := map[string]bool{}
+		for _, item := range items {
+			found[item.Label] = true
+		}
+		return found
+	}
+
+	inside := labels(at(4, 1))
+	for _, name := range []string{"total", "width", "area", "Point", "math", "print", "while"} {
+		if !inside[name] {
+			t.Errorf("Expected %s to be completed inside area", name)
+		}
+	}
+
+	outside := labels(at(17, 1))
+	if outside["total"] || outside["width"] {
+		t.Errorf("Expected locals of area to be out of scope")
+	}
+
+	c.exit()
+}
@@ -2,21 +2,92 @@ package object
 
 import (
 	"fmt"
+	"io"
 	"strings"
 )
 
 func initBuiltins(env *Environment) {
 	insertBuiltin := func(name string, fn func(...Object) (Object, error)) {
-		env.Set(name, &Builtin{Fn: fn, Name: name})
+		env.setBuiltin(&Builtin{Fn: fn, Name: name})
 	}
-	insertBuiltin("print", printFunc)
+	// insertInvoking adds a builtin which calls fener functions through the invoker of the call.
+	// Its Fn calls it without an invoker.
+	insertInvoking := func(name string, capability Capability, fn func(Invoker, ...Object) (Object, error)) {
+		invoking := func(invoker Invoker, args ...Object) (Object, error) {
+			if capability != "" && !env.Capabilities.Allows(capability) {
+				return nil, &PermissionError{Builtin: name, Capability: capability}
+			}
+			return fn(invoker, args...)
+		}
+		env.setBuiltin(&Builtin{
+			Fn:         func(args ...Object) (Object, error) { return invoking(nil, args...) },
+			Invoking:   invoking,
+			Name:       name,
+			Capability: capability,
+		})
+	}
+	// insertGuarded adds a builtin which fails unless the environment has the capability.
+	insertGuarded := func(name string, capability Capability, fn func(...Object) (Object, error)) {
+		insertInvoking(name, capability, func(_ Invoker, args ...Object) (Object, error) {
+			return fn(args...)
+		})
+	}
+	insertInvoking("print", "", func(invoker Invoker, args ...Object) (Object, error) {
+		return printFunc(invoker, env.Stdout, args...)
+	})
+	insertInvoking("eprint", "", func(invoker Invoker, args ...Object) (Object, error) {
+		return printFunc(invoker, env.Stderr, args...)
+	})
 	insertBuiltin("upper", upperFunc)
+	insertInvoking("str", "", func(invoker Invoker, args ...Object) (Object, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("wrong number of arguments for STR. got=%d, want=1", len(args))
+		}
+		out, err := Display(args[0], invoker)
+		if err != nil {
+			return nil, err
+		}
+		return &String{Value: out}, nil
+	})
+	insertInvoking("len", "", func(invoker Invoker, args ...Object) (Object, error) {
+		if len(args) == 1 {
+			if value, ok, err := CallMethod(invoker, args[0], "len"); ok {
+				return value, err
+			}
+		}
+		return lenFunc(args...)
+	})
+	insertBuiltin("freeze", freezeFunc)
+	insertBuiltin("fields", fieldsFunc)
+	insertBuiltin("methods", methodsFunc)
+	insertBuiltin("class_of", classOfFunc)
+	insertBuiltin("is_instance", isInstanceFunc)
+	insertBuiltin("implements", implementsFunc)
+	insertInvoking("map", "", mapFunc)
+	insertInvoking("filter", "", filterFunc)
+	insertInvoking("reduce", "", reduceFunc)
+	insertInvoking("sort", "", sortFunc)
+	insertInvoking("sort_by", "", sortByFunc)
+
+	insertGuarded("read_file", FS_READ_CAP, readFileFunc)
+	insertGuarded("write_file", FS_WRITE_CAP, writeFileFunc)
+	insertGuarded("getenv", ENV_CAP, getenvFunc)
+	insertGuarded("exec", EXEC_CAP, execFunc)
+	insertInvoking("fetch", NET_CAP, func(invoker Invoker, args ...Object) (Object, error) {
+		return fetchFunc(InvokerContext(invoker), args...)
+	})
+	insertGuarded("clock", CLOCK_CAP, clockFunc)
+	insertGuarded("random", RANDOM_CAP, randomFunc)
 }
-func printFunc(args ...Object) (Object, error) {
+func printFunc(invoker Invoker, w io.Writer, args ...Object) (Object, error) {
 	for _, arg := range args {
-		fmt.Print(arg.Pretty())
+		out, err := Display(arg, invoker)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprint(w, out)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	return &Null{}, nil
 }
@@ -30,6 +101,31 @@ func upperFunc(args ...Object) (Object, error) {
 	}
 	return &String{Value: strings.ToUpper(str)}, nil
 }
+func lenFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for LEN. got=%d, want=1", len(args))
+	}
+	switch arg := args[0].(type) {
+	case *String:
+		return &Integer{Value: int64(len(arg.Value))}, nil
+	case *Array:
+		return &Integer{Value: int64(len(arg.Elements))}, nil
+	case *Map:
+		return &Integer{Value: int64(len(arg.Keys))}, nil
+	case *Range:
+		return &Integer{Value: int64(arg.Len())}, nil
+	case nil:
+		return nil, fmt.Errorf("argument to LEN not supported, got nothing")
+	default:
+		return nil, fmt.Errorf("argument to LEN not supported, got %s", arg.Type())
+	}
+}
+func freezeFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for FREEZE. got=%d, want=1", len(args))
+	}
+	return Freeze(args[0]), nil
+}
 func toString(obj Object) (string, error) {
 	if obj.Type() != STRING_OBJ {
 		return "", fmt.Errorf("argument should be 'string', got %s", obj.Type())
@@ -37,3 +133,9 @@ func toString(obj Object) (string, error) {
 	str := obj.(*String)
 	return str.Value, nil
 }
+
+// BuiltinNames returns the names of every builtin in sorted order.
+func BuiltinNames() []string {
+	env := NewSandboxedEnvironment(NewCapabilities())
+	return sortedNames(env.Bindings)
+}
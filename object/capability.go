@@ -0,0 +1,96 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Capability is a permission a builtin needs from the environment.
+type Capability string
+
+const (
+	FS_READ_CAP  Capability = "fs-read"
+	FS_WRITE_CAP Capability = "fs-write"
+	ENV_CAP      Capability = "env"
+	EXEC_CAP     Capability = "exec"
+	NET_CAP      Capability = "net"
+	CLOCK_CAP    Capability = "clock"
+	RANDOM_CAP   Capability = "random"
+)
+
+var allCapabilities = []Capability{FS_READ_CAP, FS_WRITE_CAP, ENV_CAP, EXEC_CAP, NET_CAP, CLOCK_CAP, RANDOM_CAP}
+
+// Capabilities is the set of capabilities granted to an environment.
+type Capabilities map[Capability]bool
+
+func NewCapabilities(caps ...Capability) Capabilities {
+	set := Capabilities{}
+	for _, c := range caps {
+		set[c] = true
+	}
+	return set
+}
+
+func AllCapabilities() Capabilities {
+	return NewCapabilities(allCapabilities...)
+}
+
+// ParseCapabilities parses capability names, "all" grants every capability.
+func ParseCapabilities(names []string) (Capabilities, error) {
+	set := Capabilities{}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		if name == "all" {
+			return AllCapabilities(), nil
+		}
+
+		if !isCapability(Capability(name)) {
+			return nil, fmt.Errorf("unknown capability %q, expected one of %s", name, AllCapabilities())
+		}
+
+		set[Capability(name)] = true
+	}
+
+	return set, nil
+}
+
+func isCapability(c Capability) bool {
+	for _, known := range allCapabilities {
+		if known == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Capabilities) Allows(capability Capability) bool {
+	return capability == "" || c[capability]
+}
+
+func (c Capabilities) String() string {
+	names := []string{}
+	for name, ok := range c {
+		if ok {
+			names = append(names, string(name))
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// PermissionError is returned when a builtin is called without the capability it needs.
+type PermissionError struct {
+	Builtin    string
+	Capability Capability
+}
+
+func (p *PermissionError) Error() string {
+	return fmt.Sprintf("permission denied: %s needs the %q capability", p.Builtin, p.Capability)
+}
@@ -0,0 +1,54 @@
+package object
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	env := NewSandboxedEnvironment(NewCapabilities(ENV_CAP))
+
+	getenv := env.Get("getenv").(*Builtin)
+
+	if getenv.Capability != ENV_CAP {
+		t.Fatalf("Expected getenv to need %q, got %q", ENV_CAP, getenv.Capability)
+	}
+
+	if _, err := getenv.Fn(&String{Value: "HOME"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err := env.Get("read_file").(*Builtin).Fn(&String{Value: "/etc/hostname"})
+
+	var permErr *PermissionError
+	if !errors.As(err, &permErr) || permErr.Capability != FS_READ_CAP {
+		t.Fatalf("Expected permission error, got %v", err)
+	}
+
+	// Builtins without a capability are always available.
+	if _, err := env.Get("len").(*Builtin).Fn(&String{Value: "abc"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestParseCapabilities(t *testing.T) {
+	caps, err := ParseCapabilities([]string{"fs-read", " clock"})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if caps.String() != "clock, fs-read" {
+		t.Fatalf("Expected clock and fs-read, got %s", caps)
+	}
+
+	caps, err = ParseCapabilities([]string{"all"})
+
+	if err != nil || !caps.Allows(EXEC_CAP) {
+		t.Fatalf("Expected all capabilities, got %s (%v)", caps, err)
+	}
+
+	if _, err := ParseCapabilities([]string{"root"}); err == nil {
+		t.Fatalf("Expected error for unknown capability")
+	}
+}
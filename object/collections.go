@@ -0,0 +1,223 @@
+package object
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func mapFunc(invoker Invoker, args ...Object) (Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments for MAP. got=%d, want=2", len(args))
+	}
+	result := []Object{}
+	err := each("MAP", invoker, args[0], func(item Object) error {
+		value, err := Invoke(invoker, args[1], item)
+		if err != nil {
+			return err
+		}
+		result = append(result, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Array{Elements: result}, nil
+}
+func filterFunc(invoker Invoker, args ...Object) (Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments for FILTER. got=%d, want=2", len(args))
+	}
+	result := []Object{}
+	err := each("FILTER", invoker, args[0], func(item Object) error {
+		keep, err := Invoke(invoker, args[1], item)
+		if err != nil {
+			return err
+		}
+		if isTruthy(keep) {
+			result = append(result, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Array{Elements: result}, nil
+}
+
+// reduceFunc folds the elements from the left, starting from the first one without an initial value.
+func reduceFunc(invoker Invoker, args ...Object) (Object, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("wrong number of arguments for REDUCE. got=%d, want=2 or 3", len(args))
+	}
+	var result Object
+	if len(args) == 3 {
+		result = args[2]
+	}
+
+	err := each("REDUCE", invoker, args[0], func(item Object) error {
+		if result == nil {
+			result = item
+			return nil
+		}
+		var err error
+		result, err = Invoke(invoker, args[1], result, item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("REDUCE of an empty %s needs an initial value", strings.ToLower(string(args[0].Type())))
+	}
+	return result, nil
+}
+
+// sortFunc returns the elements in order, a comparator returns a negative integer when its first argument goes first.
+func sortFunc(invoker Invoker, args ...Object) (Object, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments for SORT. got=%d, want=1 or 2", len(args))
+	}
+	items, err := elements("SORT", invoker, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	compare := func(a, b Object) (int, error) { return Compare(a, b, invoker) }
+	if len(args) == 2 {
+		compare = func(a, b Object) (int, error) {
+			result, err := Invoke(invoker, args[1], a, b)
+			if err != nil {
+				return 0, err
+			}
+			order, ok := result.(*Integer)
+			if !ok {
+				return 0, fmt.Errorf("comparator must return an integer, got %s", result.Type())
+			}
+			return int(order.Value), nil
+		}
+	}
+
+	return sortKeyed(items, items, compare)
+}
+
+// sortByFunc orders the elements by the key computed for each of them once.
+func sortByFunc(invoker Invoker, args ...Object) (Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments for SORT_BY. got=%d, want=2", len(args))
+	}
+	items, err := elements("SORT_BY", invoker, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]Object, len(items))
+	for i, item := range items {
+		keys[i], err = Invoke(invoker, args[1], item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sortKeyed(items, keys, func(a, b Object) (int, error) { return Compare(a, b, invoker) })
+}
+
+// sortKeyed sorts items by their keys, keeping the order of equal keys.
+func sortKeyed(items []Object, keys []Object, compare func(a, b Object) (int, error)) (Object, error) {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	var err error
+	sort.SliceStable(order, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		var c int
+		c, err = compare(keys[order[i]], keys[order[j]])
+		return err == nil && c < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Object, len(items))
+	for i, index := range order {
+		result[i] = items[index]
+	}
+	return &Array{Elements: result}, nil
+}
+
+// Compare orders integers and strings, and instances defining lt.
+func Compare(a Object, b Object, invoker Invoker) (int, error) {
+	switch a := a.(type) {
+	case *Integer:
+		if b, ok := b.(*Integer); ok {
+			switch {
+			case a.Value < b.Value:
+				return -1, nil
+			case a.Value > b.Value:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	case *String:
+		if b, ok := b.(*String); ok {
+			return strings.Compare(a.Value, b.Value), nil
+		}
+	case *Instance:
+		less, ok, err := CallMethod(invoker, a, "lt", b)
+		if ok {
+			if err != nil {
+				return 0, err
+			}
+			if isTruthy(less) {
+				return -1, nil
+			}
+			greater, _, err := CallMethod(invoker, b, "lt", a)
+			if err != nil {
+				return 0, err
+			}
+			if isTruthy(greater) {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("can't compare %s and %s", a.Type(), b.Type())
+}
+
+// each calls fn with the elements of a list, string or range in order.
+// Every element costs a step of the invoker's budget, so walking a long range can be stopped.
+func each(name string, invoker Invoker, obj Object, fn func(item Object) error) error {
+	sequence, ok := obj.(Sequence)
+	if !ok {
+		return fmt.Errorf("first argument to %s must be a list, string or range, got %s", name, obj.Type())
+	}
+	length := sequence.Len()
+	for i := 0; i < length; i++ {
+		if err := Step(invoker); err != nil {
+			return err
+		}
+		if err := fn(sequence.At(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// elements returns the elements of a list, string or range, see each.
+func elements(name string, invoker Invoker, obj Object) ([]Object, error) {
+	items := []Object{}
+	err := each(name, invoker, obj, func(item Object) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,471 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	objectType = reflect.TypeOf((*Object)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// ToObject converts a Go value to a fener object.
+// Structs become instances, their fields can be renamed with a `fener:"name"` tag.
+func ToObject(value interface{}) (Object, error) {
+	return toObject(reflect.ValueOf(value))
+}
+
+func toObject(v reflect.Value) (Object, error) {
+	if !v.IsValid() {
+		return &Null{}, nil
+	}
+
+	if v.Type().Implements(objectType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return &Null{}, nil
+		}
+		return v.Interface().(Object), nil
+	}
+
+	if v.Type().Implements(errorType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return &Null{}, nil
+		}
+		return &String{Value: v.Interface().(error).Error()}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return &Null{}, nil
+		}
+		return toObject(v.Elem())
+	case reflect.Bool:
+		return &Boolean{Value: v.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Integer{Value: int64(v.Uint())}, nil
+	case reflect.String:
+		return &String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return &Null{}, nil
+		}
+		return toArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			return &Null{}, nil
+		}
+		return toMap(v)
+	case reflect.Struct:
+		return toInstance(v)
+	case reflect.Func:
+		if v.IsNil() {
+			return &Null{}, nil
+		}
+		return NewBuiltin(v.Type().String(), v.Interface())
+	default:
+		return nil, fmt.Errorf("can't convert %s to a fener value", v.Type())
+	}
+}
+
+func toArray(v reflect.Value) (Object, error) {
+	elements := []Object{}
+
+	for i := 0; i < v.Len(); i++ {
+		element, err := toObject(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	return &Array{Elements: elements}, nil
+}
+
+func toMap(v reflect.Value) (Object, error) {
+	m := NewMap()
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := toObject(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := toObject(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func toInstance(v reflect.Value) (Object, error) {
+	klass := &Class{Name: v.Type().Name(), Methods: map[string]*Function{}}
+
+	instance := &Instance{
+		Class:   klass,
+		Map:     make(map[string]Object),
+		Methods: klass.Methods,
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		name, ok := fieldName(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		value, err := toObject(v.Field(i))
+		if err != nil {
+			return nil, err
+		}
+
+		instance.Set(name, value)
+	}
+
+	return instance, nil
+}
+
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	tag := field.Tag.Get("fener")
+
+	if tag == "-" {
+		return "", false
+	}
+
+	if tag != "" {
+		return tag, true
+	}
+
+	return field.Name, true
+}
+
+// FromObject converts a fener object to a Go value of type t.
+func FromObject(obj Object, t reflect.Type) (reflect.Value, error) {
+	if obj == nil {
+		obj = &Null{}
+	}
+
+	if t.Implements(objectType) && reflect.TypeOf(obj).AssignableTo(t) {
+		return reflect.ValueOf(obj), nil
+	}
+
+	if t == errorType {
+		switch obj := obj.(type) {
+		case *Null:
+			return reflect.Zero(t), nil
+		case *String:
+			return reflect.ValueOf(errors.New(obj.Value)), nil
+		}
+		return reflect.Value{}, mismatch(t, obj)
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() != 0 {
+			return reflect.Value{}, mismatch(t, obj)
+		}
+		if _, ok := obj.(*Null); ok {
+			return reflect.Zero(t), nil
+		}
+		return FromObject(obj, naturalType(obj))
+	case reflect.Pointer:
+		if _, ok := obj.(*Null); ok {
+			return reflect.Zero(t), nil
+		}
+		elem, err := FromObject(obj, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case reflect.Bool:
+		b, ok := obj.(*Boolean)
+		if !ok {
+			return reflect.Value{}, mismatch(t, obj)
+		}
+		return reflect.ValueOf(b.Value).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := obj.(*Integer)
+		if !ok {
+			return reflect.Value{}, mismatch(t, obj)
+		}
+		v := reflect.New(t).Elem()
+		if v.OverflowInt(i.Value) {
+			return reflect.Value{}, fmt.Errorf("%d overflows %s", i.Value, t)
+		}
+		v.SetInt(i.Value)
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, ok := obj.(*Integer)
+		if !ok {
+			return reflect.Value{}, mismatch(t, obj)
+		}
+		v := reflect.New(t).Elem()
+		if i.Value < 0 || v.OverflowUint(uint64(i.Value)) {
+			return reflect.Value{}, fmt.Errorf("%d overflows %s", i.Value, t)
+		}
+		v.SetUint(uint64(i.Value))
+		return v, nil
+	case reflect.String:
+		s, ok := obj.(*String)
+		if !ok {
+			return reflect.Value{}, mismatch(t, obj)
+		}
+		return reflect.ValueOf(s.Value).Convert(t), nil
+	case reflect.Slice:
+		return fromArray(obj, t)
+	case reflect.Map:
+		return fromMap(obj, t)
+	case reflect.Struct:
+		return fromInstance(obj, t)
+	default:
+		return reflect.Value{}, fmt.Errorf("can't convert %s to %s", obj.Type(), t)
+	}
+}
+
+// naturalType picks the Go type a fener object converts to when any type is accepted.
+func naturalType(obj Object) reflect.Type {
+	switch obj.(type) {
+	case *Integer:
+		return reflect.TypeOf(int64(0))
+	case *String:
+		return reflect.TypeOf("")
+	case *Boolean:
+		return reflect.TypeOf(false)
+	case *Array:
+		return reflect.TypeOf([]interface{}{})
+	case *Map:
+		return reflect.TypeOf(map[interface{}]interface{}{})
+	default:
+		return objectType
+	}
+}
+
+func fromArray(obj Object, t reflect.Type) (reflect.Value, error) {
+	if _, ok := obj.(*Null); ok {
+		return reflect.Zero(t), nil
+	}
+
+	array, ok := obj.(*Array)
+	if !ok {
+		return reflect.Value{}, mismatch(t, obj)
+	}
+
+	slice := reflect.MakeSlice(t, 0, len(array.Elements))
+
+	for i, element := range array.Elements {
+		v, err := FromObject(element, t.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("element %d: %w", i+1, err)
+		}
+		slice = reflect.Append(slice, v)
+	}
+
+	return slice, nil
+}
+
+func fromMap(obj Object, t reflect.Type) (reflect.Value, error) {
+	if _, ok := obj.(*Null); ok {
+		return reflect.Zero(t), nil
+	}
+
+	m, ok := obj.(*Map)
+	if !ok {
+		return reflect.Value{}, mismatch(t, obj)
+	}
+
+	result := reflect.MakeMapWithSize(t, len(m.Keys))
+
+	for _, pair := range m.Items() {
+		key, err := FromObject(pair.Key, t.Key())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %s: %w", pair.Key.Pretty(), err)
+		}
+
+		value, err := FromObject(pair.Value, t.Elem())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("value for %s: %w", pair.Key.Pretty(), err)
+		}
+
+		result.SetMapIndex(key, value)
+	}
+
+	return result, nil
+}
+
+func fromInstance(obj Object, t reflect.Type) (reflect.Value, error) {
+	var lookup func(string) (Object, bool)
+
+	switch obj := obj.(type) {
+	case *Instance:
+		lookup = func(name string) (Object, bool) {
+			value, ok := obj.Map[name]
+			return value, ok
+		}
+	case *Map:
+		lookup = func(name string) (Object, bool) {
+			return obj.Get(&String{Value: name})
+		}
+	default:
+		return reflect.Value{}, mismatch(t, obj)
+	}
+
+	result := reflect.New(t).Elem()
+
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+
+		v, err := FromObject(value, t.Field(i).Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("field %s: %w", name, err)
+		}
+
+		result.Field(i).Set(v)
+	}
+
+	return result, nil
+}
+
+func mismatch(t reflect.Type, obj Object) error {
+	return fmt.Errorf("expected %s, got %s", typeName(t), obj.Type())
+}
+
+// typeName describes a Go type in terms of fener types.
+func typeName(t reflect.Type) string {
+	if t == errorType {
+		return "error"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "int"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		return "array"
+	case reflect.Map:
+		return "map"
+	case reflect.Struct:
+		return t.Name()
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	default:
+		return t.String()
+	}
+}
+
+// NewBuiltin wraps any Go function as a builtin.
+// Arguments and results are converted with FromObject and ToObject,
+// a non-nil error as the last result is reported as an error in fener.
+func NewBuiltin(name string, fn interface{}) (*Builtin, error) {
+	v := reflect.ValueOf(fn)
+
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("can't register %s, expected a function, got %T", name, fn)
+	}
+
+	t := v.Type()
+
+	returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+
+	if t.NumOut() > 2 || (t.NumOut() == 2 && !returnsError) {
+		return nil, fmt.Errorf("can't register %s, functions can return a value and an error, got %s", name, t)
+	}
+
+	call := func(args ...Object) (Object, error) {
+		in, err := convertArguments(name, t, args)
+		if err != nil {
+			return nil, err
+		}
+
+		out := v.Call(in)
+
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, err
+			}
+			out = out[:len(out)-1]
+		}
+
+		if len(out) == 0 {
+			return &Null{}, nil
+		}
+
+		return toObject(out[0])
+	}
+
+	return &Builtin{Name: name, Fn: call}, nil
+}
+
+func convertArguments(name string, t reflect.Type, args []Object) ([]reflect.Value, error) {
+	fixed := t.NumIn()
+
+	if t.IsVariadic() {
+		fixed--
+		if len(args) < fixed {
+			return nil, fmt.Errorf("wrong number of arguments for %s. got=%d, want at least %d", name, len(args), fixed)
+		}
+	} else if len(args) != fixed {
+		return nil, fmt.Errorf("wrong number of arguments for %s. got=%d, want=%d", name, len(args), fixed)
+	}
+
+	in := []reflect.Value{}
+
+	for i, arg := range args {
+		var paramType reflect.Type
+
+		if i < fixed {
+			paramType = t.In(i)
+		} else {
+			paramType = t.In(fixed).Elem()
+		}
+
+		v, err := FromObject(arg, paramType)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d to %s: %w", i+1, name, err)
+		}
+
+		in = append(in, v)
+	}
+
+	return in, nil
+}
+
+// RegisterFunc binds a Go function as a builtin in env.
+func RegisterFunc(env *Environment, name string, fn interface{}) error {
+	builtin, err := NewBuiltin(name, fn)
+
+	if err != nil {
+		return err
+	}
+
+	env.Set(name, builtin)
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package object
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	X     int
+	Y     int
+	Label string `fener:"label"`
+	skip  int
+}
+
+func TestToObject(t *testing.T) {
+	table := []struct {
+		input    interface{}
+		expected string
+	}{
+		{42, "int(42)"},
+		{uint8(7), "int(7)"},
+		{"fener", "str(fener)"},
+		{true, "bool(true)"},
+		{nil, "null"},
+		{[]int{1, 2}, "[int(1) int(2)]"},
+		{map[string]int{"a": 1}, "{str(a)->int(1)}"},
+		{errors.New("failed"), "str(failed)"},
+		{&Integer{Value: 3}, "int(3)"},
+	}
+
+	for _, tt := range table {
+		obj, err := ToObject(tt.input)
+
+		if err != nil {
+			t.Fatalf("Unexpected error converting %v: %v", tt.input, err)
+		}
+
+		if obj.String() != tt.expected {
+			t.Fatalf("Expected %s, got %s", tt.expected, obj.String())
+		}
+	}
+}
+
+func TestStructConversion(t *testing.T) {
+	obj, err := ToObject(point{X: 1, Y: 2, Label: "origin", skip: 3})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	instance, ok := obj.(*Instance)
+
+	if !ok {
+		t.Fatalf("Expected instance, got %T", obj)
+	}
+
+	if _, ok := instance.Get("skip"); ok {
+		t.Fatalf("Unexported fields should not be converted")
+	}
+
+	label, _ := instance.Get("label")
+
+	if label.Pretty() != "origin" {
+		t.Fatalf("Expected label to be origin, got %s", label.Pretty())
+	}
+
+	value, err := FromObject(instance, reflect.TypeOf(point{}))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if value.Interface().(point) != (point{X: 1, Y: 2, Label: "origin"}) {
+		t.Fatalf("Expected struct to round trip, got %+v", value.Interface())
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	m := NewMap()
+	m.Set(&String{Value: "a"}, &Integer{Value: 1})
+
+	value, err := FromObject(m, reflect.TypeOf(map[string]int{}))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(value.Interface(), map[string]int{"a": 1}) {
+		t.Fatalf("Expected map to convert, got %v", value.Interface())
+	}
+
+	array := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "two"}}}
+
+	_, err = FromObject(array, reflect.TypeOf([]int{}))
+
+	if err == nil || err.Error() != "element 2: expected int, got STRING" {
+		t.Fatalf("Expected element mismatch error, got %v", err)
+	}
+
+	_, err = FromObject(&Integer{Value: 300}, reflect.TypeOf(uint8(0)))
+
+	if err == nil {
+		t.Fatalf("Expected overflow error")
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	env := NewEnvironment()
+
+	err := RegisterFunc(env, "join", func(sep string, parts ...string) string {
+		return strings.Join(parts, sep)
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = RegisterFunc(env, "divide", func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return a / b, nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	join := env.Get("join").(*Builtin)
+
+	value, err := join.Fn(&String{Value: "-"}, &String{Value: "a"}, &String{Value: "b"})
+
+	if err != nil || value.Pretty() != "a-b" {
+		t.Fatalf("Expected a-b, got %v (%v)", value, err)
+	}
+
+	divide := env.Get("divide").(*Builtin)
+
+	table := []struct {
+		args     []Object
+		expected string
+	}{
+		{[]Object{&Integer{Value: 1}}, "wrong number of arguments for divide. got=1, want=2"},
+		{[]Object{&Integer{Value: 1}, &String{Value: "2"}}, "argument 2 to divide: expected int, got STRING"},
+		{[]Object{&Integer{Value: 1}, &Integer{Value: 0}}, "division by zero"},
+	}
+
+	for _, tt := range table {
+		_, err := divide.Fn(tt.args...)
+
+		if err == nil || err.Error() != tt.expected {
+			t.Fatalf("Expected error %q, got %v", tt.expected, err)
+		}
+	}
+
+	if err := RegisterFunc(env, "broken", 5); err == nil {
+		t.Fatalf("Expected error registering a non function")
+	}
+}
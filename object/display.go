@@ -0,0 +1,116 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// displayMethods are the methods an instance defines to render itself, in order of preference.
+var displayMethods = []string{"to_string", "str"}
+
+// Display renders obj like print does, invoker is used to call `to_string` and may be nil.
+func Display(obj Object, invoker Invoker) (string, error) {
+	p := newPrinter(invoker, true)
+	out := p.print(obj)
+	return out, p.err
+}
+
+// Inspect renders obj like the REPL does, invoker is used to call `to_string` and may be nil.
+func Inspect(obj Object, invoker Invoker) (string, error) {
+	p := newPrinter(invoker, false)
+	out := p.print(obj)
+	return out, p.err
+}
+
+// printer renders nested values, a value reached again while it's being printed is shown as `...`.
+type printer struct {
+	invoker Invoker
+	pretty  bool
+	seen    map[Object]bool
+	err     error
+}
+
+func newPrinter(invoker Invoker, pretty bool) *printer {
+	return &printer{invoker: invoker, pretty: pretty, seen: map[Object]bool{}}
+}
+
+func (p *printer) print(obj Object) string {
+	switch obj := obj.(type) {
+	case *Array:
+		if p.seen[obj] {
+			return "[...]"
+		}
+		p.seen[obj] = true
+		defer delete(p.seen, obj)
+
+		elements := []string{}
+		for _, element := range obj.Elements {
+			elements = append(elements, p.print(element))
+		}
+		return fmt.Sprintf("[%s]", strings.Join(elements, p.separator()))
+	case *Map:
+		if p.seen[obj] {
+			return "{...}"
+		}
+		p.seen[obj] = true
+		defer delete(p.seen, obj)
+
+		pairs := []string{}
+		for _, pair := range obj.Items() {
+			pairs = append(pairs, p.pair(p.print(pair.Key), pair.Value))
+		}
+		return fmt.Sprintf("{%s}", strings.Join(pairs, p.separator()))
+	case *Instance:
+		if out, ok := p.custom(obj); ok {
+			return out
+		}
+		if p.seen[obj] {
+			return obj.Class.Name + "{...}"
+		}
+		p.seen[obj] = true
+		defer delete(p.seen, obj)
+
+		fields := []string{}
+		for _, name := range sortedNames(obj.Map) {
+			fields = append(fields, p.pair(name, obj.Map[name]))
+		}
+		return fmt.Sprintf("%s{%s}", obj.Class.Name, strings.Join(fields, p.separator()))
+	default:
+		if p.pretty {
+			return obj.Pretty()
+		}
+		return obj.String()
+	}
+}
+
+// custom calls the instance's own rendering method, if the class defines one.
+func (p *printer) custom(instance *Instance) (string, bool) {
+	for _, name := range displayMethods {
+		value, ok, err := CallMethod(p.invoker, instance, name)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			if p.err == nil {
+				p.err = err
+			}
+			return "", true
+		}
+		return value.Pretty(), true
+	}
+	return "", false
+}
+
+func (p *printer) pair(key string, value Object) string {
+	if p.pretty {
+		return fmt.Sprintf("%s = %s", key, p.print(value))
+	}
+	return fmt.Sprintf("%s->%s", key, p.print(value))
+}
+
+func (p *printer) separator() string {
+	if p.pretty {
+		return ", "
+	}
+	return " "
+}
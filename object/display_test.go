@@ -0,0 +1,44 @@
+package object
+
+import "testing"
+
+func TestDisplay(t *testing.T) {
+	class := &Class{Name: "Node", Methods: map[string]*Function{}}
+
+	node := &Instance{Class: class, Map: map[string]Object{
+		"value": &Integer{Value: 1},
+		"name":  &String{Value: "a"},
+	}, Methods: class.Methods}
+
+	cyclic := &Instance{Class: class, Map: map[string]Object{}, Methods: class.Methods}
+	cyclic.Map["next"] = cyclic
+
+	xs := &Array{Elements: []Object{&Integer{Value: 1}}}
+	xs.Elements = append(xs.Elements, xs)
+
+	shared := &Array{Elements: []Object{&Integer{Value: 2}}}
+	twice := &Array{Elements: []Object{shared, shared}}
+
+	tt := []struct {
+		value   Object
+		pretty  string
+		inspect string
+	}{
+		{node, "Node{name = a, value = 1}", "Node{name->str(a) value->int(1)}"},
+		{cyclic, "Node{next = Node{...}}", "Node{next->Node{...}}"},
+		{xs, "[1, [...]]", "[int(1) [...]]"},
+		{twice, "[[2], [2]]", "[[int(2)] [int(2)]]"},
+	}
+
+	for _, tc := range tt {
+		pretty, err := Display(tc.value, nil)
+		if err != nil || pretty != tc.pretty {
+			t.Errorf("Expected %q, got %q (%v)", tc.pretty, pretty, err)
+		}
+
+		inspect, err := Inspect(tc.value, nil)
+		if err != nil || inspect != tc.inspect {
+			t.Errorf("Expected %q, got %q (%v)", tc.inspect, inspect, err)
+		}
+	}
+}
@@ -1,21 +1,156 @@
 package object
 
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
 type Environment struct {
 	Outer    *Environment
 	Bindings map[string]Object
+
+	// Stdout and Stderr are used by the builtins, only the outermost environment's writers are used.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Capabilities are checked by the builtins when they are called.
+	Capabilities Capabilities
+
+	constants map[string]bool
+	// builtins are the builtins the environment was created with.
+	builtins map[string]Object
+}
+
+// ConstError is returned when a constant is assigned to.
+type ConstError struct {
+	Name string
+}
+
+func (c *ConstError) Error() string {
+	return fmt.Sprintf("can't assign to constant %s", c.Name)
 }
 
+// NewEnvironment creates an environment whose builtins have every capability.
 func NewEnvironment() *Environment {
+	return NewSandboxedEnvironment(AllCapabilities())
+}
+
+// NewSandboxedEnvironment creates an environment whose builtins only have the given capabilities.
+func NewSandboxedEnvironment(caps Capabilities) *Environment {
 	env := &Environment{
-		Outer:    nil,
-		Bindings: make(map[string]Object),
+		Outer:        nil,
+		Bindings:     make(map[string]Object),
+		Stdout:       os.Stdout,
+		Stderr:       os.Stderr,
+		Capabilities: caps,
 	}
 	initBuiltins(env)
 	return env
 }
+
+// NewEnclosedEnvironment creates a scope inside outer, it shares the builtins of outer.
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	return &Environment{
+		Outer:    outer,
+		Bindings: make(map[string]Object),
+	}
+}
+
+// NewSiblingEnvironment creates a fresh top-level environment with the same output and capabilities as env.
+func NewSiblingEnvironment(env *Environment) *Environment {
+	root := env.Root()
+
+	sibling := NewSandboxedEnvironment(root.Capabilities)
+	sibling.Stdout = root.Stdout
+	sibling.Stderr = root.Stderr
+
+	return sibling
+}
+
+// setBuiltin binds one of the builtins the environment is created with.
+func (e *Environment) setBuiltin(builtin *Builtin) {
+	if e.builtins == nil {
+		e.builtins = make(map[string]Object)
+	}
+
+	e.builtins[builtin.Name] = builtin
+	e.Set(builtin.Name, builtin)
+}
+
+// isBuiltin reports whether name is still bound to the builtin the environment was created with.
+func (e *Environment) isBuiltin(name string, value Object) bool {
+	builtin, ok := e.builtins[name]
+	return ok && builtin == value
+}
+
+func (e *Environment) Root() *Environment {
+	if e.Outer == nil {
+		return e
+	}
+	return e.Outer.Root()
+}
+
+// Set binds name in e without checking for constants, it's meant for the host.
 func (e *Environment) Set(name string, value Object) {
 	e.Bindings[name] = value
 }
+
+// Define binds name in e, it fails if name is a constant of e.
+func (e *Environment) Define(name string, value Object) error {
+	if e.constants[name] {
+		return &ConstError{Name: name}
+	}
+
+	e.Set(name, value)
+	return nil
+}
+
+// DefineConst binds name in e as a constant.
+func (e *Environment) DefineConst(name string, value Object) error {
+	if err := e.Define(name, value); err != nil {
+		return err
+	}
+
+	if e.constants == nil {
+		e.constants = make(map[string]bool)
+	}
+
+	e.constants[name] = true
+	return nil
+}
+
+// IsConst reports whether the nearest binding of name is a constant.
+func (e *Environment) IsConst(name string) bool {
+	for env := e; env != nil; env = env.Outer {
+		if _, ok := env.Bindings[name]; ok {
+			return env.constants[name]
+		}
+	}
+	return false
+}
+
+// Assign updates the nearest binding of name, or binds it in e when no scope has it.
+// Builtins are never updated from an inner scope, assigning to one shadows it in e.
+func (e *Environment) Assign(name string, value Object) error {
+	for env := e; env != nil; env = env.Outer {
+		current, ok := env.Bindings[name]
+
+		if !ok {
+			continue
+		}
+
+		if _, builtin := current.(*Builtin); builtin && env != e {
+			break
+		}
+
+		return env.Define(name, value)
+	}
+
+	e.Set(name, value)
+	return nil
+}
 func (e *Environment) Get(name string) Object {
 	obj, ok := e.Bindings[name]
 
@@ -25,3 +160,15 @@ func (e *Environment) Get(name string) Object {
 
 	return obj
 }
+
+func sortedNames[T any](bindings map[string]T) []string {
+	names := []string{}
+
+	for name := range bindings {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,126 @@
+package object
+
+// Equal compares values by content, arrays, maps and instances are compared element by element.
+// Instances defining eq are compared by calling it through invoker.
+func Equal(left Object, right Object, invoker Invoker) (bool, error) {
+	c := &comparer{invoker: invoker, seen: map[[2]Object]bool{}}
+	return c.equal(left, right)
+}
+
+type comparer struct {
+	invoker Invoker
+	// seen holds the pairs being compared, a pair met again is assumed equal so cycles end.
+	seen map[[2]Object]bool
+}
+
+func (c *comparer) equal(left Object, right Object) (bool, error) {
+	if left == nil || right == nil {
+		return left == right, nil
+	}
+
+	if left.Type() != right.Type() {
+		return false, nil
+	}
+
+	pair := [2]Object{left, right}
+	if c.seen[pair] {
+		return true, nil
+	}
+	c.seen[pair] = true
+	defer delete(c.seen, pair)
+
+	switch left := left.(type) {
+	case *Integer:
+		return left.Value == right.(*Integer).Value, nil
+	case *String:
+		return left.Value == right.(*String).Value, nil
+	case *Boolean:
+		return left.Value == right.(*Boolean).Value, nil
+	case *Null:
+		return true, nil
+	case *Range:
+		// Ranges holding the same integers are equal, however they were written.
+		other := right.(*Range)
+		return left.Len() == other.Len() && (left.Len() == 0 || left.Start == other.Start), nil
+	case *Array:
+		return c.equalArrays(left, right.(*Array))
+	case *Map:
+		return c.equalMaps(left, right.(*Map))
+	case *Instance:
+		return c.equalInstances(left, right.(*Instance))
+	default:
+		return left == right, nil
+	}
+}
+
+func (c *comparer) equalArrays(left *Array, right *Array) (bool, error) {
+	if len(left.Elements) != len(right.Elements) {
+		return false, nil
+	}
+
+	for i := range left.Elements {
+		if ok, err := c.equal(left.Elements[i], right.Elements[i]); !ok || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (c *comparer) equalMaps(left *Map, right *Map) (bool, error) {
+	if len(left.Keys) != len(right.Keys) {
+		return false, nil
+	}
+
+	for key, pair := range left.Pairs {
+		other, ok := right.Pairs[key]
+		if !ok {
+			return false, nil
+		}
+		if ok, err := c.equal(pair.Value, other.Value); !ok || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (c *comparer) equalInstances(left *Instance, right *Instance) (bool, error) {
+	if value, ok, err := CallMethod(c.invoker, left, "eq", right); ok {
+		if err != nil {
+			return false, err
+		}
+		return isTruthy(value), nil
+	}
+
+	if left == right {
+		return true, nil
+	}
+
+	if left.Class != right.Class || len(left.Map) != len(right.Map) {
+		return false, nil
+	}
+
+	for name, value := range left.Map {
+		other, ok := right.Map[name]
+		if !ok {
+			return false, nil
+		}
+		if ok, err := c.equal(value, other); !ok || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func isTruthy(obj Object) bool {
+	switch obj := obj.(type) {
+	case *Integer:
+		return obj.Value != 0
+	case *Boolean:
+		return obj.Value
+	default:
+		return false
+	}
+}
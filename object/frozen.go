@@ -0,0 +1,46 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrozenError is returned when a frozen array, map or instance is modified.
+type FrozenError struct {
+	Type ObjectType
+}
+
+func (f *FrozenError) Error() string {
+	return fmt.Sprintf("can't modify a frozen %s", strings.ToLower(string(f.Type)))
+}
+
+// Freeze makes obj and every array, map and instance reachable from it reject mutation.
+func Freeze(obj Object) Object {
+	switch obj := obj.(type) {
+	case *Array:
+		if obj.Frozen {
+			return obj
+		}
+		obj.Frozen = true
+		for _, element := range obj.Elements {
+			Freeze(element)
+		}
+	case *Map:
+		if obj.Frozen {
+			return obj
+		}
+		obj.Frozen = true
+		for _, pair := range obj.Pairs {
+			Freeze(pair.Value)
+		}
+	case *Instance:
+		if obj.Frozen {
+			return obj
+		}
+		obj.Frozen = true
+		for _, value := range obj.Map {
+			Freeze(value)
+		}
+	}
+	return obj
+}
@@ -0,0 +1,77 @@
+package object
+
+import "fmt"
+
+func fieldsFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for FIELDS. got=%d, want=1", len(args))
+	}
+	instance, ok := args[0].(*Instance)
+	if !ok {
+		return nil, fmt.Errorf("argument to FIELDS must be an instance, got %s", args[0].Type())
+	}
+	return names(sortedNames(instance.Map)), nil
+}
+
+// methodsFunc lists the instance methods of a class, or of the class of an instance.
+func methodsFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for METHODS. got=%d, want=1", len(args))
+	}
+	switch arg := args[0].(type) {
+	case *Class:
+		return names(sortedNames(arg.Methods)), nil
+	case *Instance:
+		return names(sortedNames(arg.Methods)), nil
+	default:
+		return nil, fmt.Errorf("argument to METHODS must be a class or an instance, got %s", arg.Type())
+	}
+}
+func classOfFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for CLASS_OF. got=%d, want=1", len(args))
+	}
+	instance, ok := args[0].(*Instance)
+	if !ok {
+		return nil, fmt.Errorf("argument to CLASS_OF must be an instance, got %s", args[0].Type())
+	}
+	return instance.Class, nil
+}
+func isInstanceFunc(args ...Object) (Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments for IS_INSTANCE. got=%d, want=2", len(args))
+	}
+	class, ok := args[1].(*Class)
+	if !ok {
+		return nil, fmt.Errorf("second argument to IS_INSTANCE must be a class, got %s", args[1].Type())
+	}
+	instance, ok := args[0].(*Instance)
+	return &Boolean{Value: ok && instance.Class == class}, nil
+}
+
+// implementsFunc checks whether an instance or a class includes a trait.
+func implementsFunc(args ...Object) (Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments for IMPLEMENTS. got=%d, want=2", len(args))
+	}
+	trait, ok := args[1].(*Trait)
+	if !ok {
+		return nil, fmt.Errorf("second argument to IMPLEMENTS must be a trait, got %s", args[1].Type())
+	}
+	switch arg := args[0].(type) {
+	case *Instance:
+		return &Boolean{Value: arg.Class.Implements(trait)}, nil
+	case *Class:
+		return &Boolean{Value: arg.Implements(trait)}, nil
+	default:
+		return &Boolean{Value: false}, nil
+	}
+}
+
+func names(values []string) *Array {
+	elements := make([]Object, len(values))
+	for i, value := range values {
+		elements[i] = &String{Value: value}
+	}
+	return &Array{Elements: elements}
+}
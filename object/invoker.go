@@ -0,0 +1,94 @@
+package object
+
+import (
+	"context"
+	"fmt"
+)
+
+// Invoker calls fener functions, bound methods, builtins and classes on behalf of Go code.
+type Invoker interface {
+	Invoke(fn Object, args ...Object) (Object, error)
+}
+
+// Invoke calls fn with args, builtins are called directly and everything else through invoker.
+func Invoke(invoker Invoker, fn Object, args ...Object) (Object, error) {
+	if builtin, ok := fn.(*Builtin); ok {
+		return builtin.Call(invoker, args...)
+	}
+
+	if invoker == nil {
+		return nil, fmt.Errorf("can't call %s without an invoker", fn.Type())
+	}
+
+	return invoker.Invoke(fn, args...)
+}
+
+// Call calls the builtin on behalf of invoker, which may be nil.
+func (b *Builtin) Call(invoker Invoker, args ...Object) (Object, error) {
+	if b.Invoking != nil {
+		return b.Invoking(invoker, args...)
+	}
+	return b.Fn(args...)
+}
+
+// InvokerContext returns the context of the run invoker belongs to, builtins waiting on I/O stop when it's done.
+// Invokers without a Context method never cancel.
+func InvokerContext(invoker Invoker) context.Context {
+	if c, ok := invoker.(interface{ Context() context.Context }); ok {
+		if ctx := c.Context(); ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// Step charges a unit of work done by a builtin to the budget of the run invoker belongs to.
+// Invokers without a Step method have no budget.
+func Step(invoker Invoker) error {
+	if s, ok := invoker.(interface{ Step() error }); ok {
+		return s.Step()
+	}
+	return nil
+}
+
+// Bind returns method with `this` bound to the instance.
+func (i *Instance) Bind(method *Function) *Function {
+	env := NewEnclosedEnvironment(method.Env)
+	env.Set("this", i)
+
+	bound := *method
+	bound.Env = env
+
+	return &bound
+}
+
+// Method returns the bound method name if obj is an instance whose class defines it.
+func Method(obj Object, name string) (*Function, bool) {
+	instance, ok := obj.(*Instance)
+	if !ok {
+		return nil, false
+	}
+
+	method, ok := instance.Methods[name]
+	if !ok {
+		return nil, false
+	}
+
+	return instance.Bind(method), true
+}
+
+// CallMethod calls the method name of obj through invoker, ok is false if obj doesn't define it.
+func CallMethod(invoker Invoker, obj Object, name string, args ...Object) (Object, bool, error) {
+	method, ok := Method(obj, name)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if invoker == nil {
+		return nil, true, fmt.Errorf("can't call method %s without an invoker", name)
+	}
+
+	result, err := invoker.Invoke(method, args...)
+
+	return result, true, err
+}
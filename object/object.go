@@ -20,7 +20,14 @@ const (
 	RETURN_OBJ   = "RETURN"
 
 	CLASS_OBJ    = "CLASS"
+	TRAIT_OBJ    = "TRAIT"
 	INSTANCE_OBJ = "INSTANCE"
+
+	MODULE_OBJ = "MODULE"
+
+	ARRAY_OBJ = "ARRAY"
+	MAP_OBJ   = "MAP"
+	RANGE_OBJ = "RANGE"
 )
 
 type Object interface {
@@ -50,6 +57,12 @@ func (s *String) String() string {
 	return fmt.Sprintf("str(%s)", s.Value)
 }
 func (s *String) Pretty() string { return s.Value }
+func (s *String) Len() int       { return len(s.Value) }
+
+// At returns the byte at the 0-based index as a string.
+func (s *String) At(index int) Object {
+	return &String{Value: string(s.Value[index])}
+}
 
 type Boolean struct {
 	Value bool
@@ -68,10 +81,121 @@ func (n *Null) Type() ObjectType { return NULL_OBJ }
 func (n *Null) String() string   { return "null" }
 func (n *Null) Pretty() string   { return "null" }
 
+// HashKey identifies a value used as a map key.
+type HashKey struct {
+	Type  ObjectType
+	Value string
+}
+
+// Hashable is implemented by objects that can be used as map keys.
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey { return HashKey{Type: i.Type(), Value: i.Pretty()} }
+func (s *String) HashKey() HashKey  { return HashKey{Type: s.Type(), Value: s.Value} }
+func (b *Boolean) HashKey() HashKey { return HashKey{Type: b.Type(), Value: b.Pretty()} }
+
+type Array struct {
+	Elements []Object
+	Frozen   bool
+}
+
+func (a *Array) Type() ObjectType    { return ARRAY_OBJ }
+func (a *Array) String() string      { return newPrinter(nil, false).print(a) }
+func (a *Array) Pretty() string      { return newPrinter(nil, true).print(a) }
+func (a *Array) Len() int            { return len(a.Elements) }
+func (a *Array) At(index int) Object { return a.Elements[index] }
+
+// Set replaces the element at the 0-based index.
+func (a *Array) Set(index int, value Object) error {
+	if a.Frozen {
+		return &FrozenError{Type: a.Type()}
+	}
+	a.Elements[index] = value
+	return nil
+}
+
+type MapPair struct {
+	Key   Object
+	Value Object
+}
+
+// Map keeps its pairs in insertion order.
+type Map struct {
+	Pairs  map[HashKey]*MapPair
+	Keys   []HashKey
+	Frozen bool
+}
+
+func NewMap() *Map {
+	return &Map{
+		Pairs: make(map[HashKey]*MapPair),
+		Keys:  []HashKey{},
+	}
+}
+
+func (m *Map) Type() ObjectType { return MAP_OBJ }
+func (m *Map) String() string   { return newPrinter(nil, false).print(m) }
+func (m *Map) Pretty() string   { return newPrinter(nil, true).print(m) }
+func (m *Map) Set(key Object, value Object) error {
+	if m.Frozen {
+		return &FrozenError{Type: m.Type()}
+	}
+
+	hashable, ok := key.(Hashable)
+
+	if !ok {
+		return fmt.Errorf("unusable as map key: %s", key.Type())
+	}
+
+	hash := hashable.HashKey()
+
+	if _, ok := m.Pairs[hash]; !ok {
+		m.Keys = append(m.Keys, hash)
+	}
+
+	m.Pairs[hash] = &MapPair{Key: key, Value: value}
+
+	return nil
+}
+func (m *Map) Get(key Object) (Object, bool) {
+	hashable, ok := key.(Hashable)
+
+	if !ok {
+		return nil, false
+	}
+
+	pair, ok := m.Pairs[hashable.HashKey()]
+
+	if !ok {
+		return nil, false
+	}
+
+	return pair.Value, true
+}
+
+// Items returns the pairs in insertion order.
+func (m *Map) Items() []*MapPair {
+	items := []*MapPair{}
+	for _, key := range m.Keys {
+		items = append(items, m.Pairs[key])
+	}
+	return items
+}
+
 type Function struct {
+	// Name is empty for lambdas.
+	Name      string
 	Env       *Environment
 	Arguments []string
-	Body      *ast.BlockStatement
+	// Defaults are evaluated in the call's environment when their argument is missing.
+	Defaults map[string]ast.Expression
+	// Rest is the parameter collecting extra positional arguments, empty if there is none.
+	Rest string
+	// Patterns destructure the arguments of the parameters named like them.
+	Patterns map[string]ast.Expression
+	Body     *ast.BlockStatement
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -83,6 +207,13 @@ func (f *Function) Pretty() string { return f.String() }
 type Builtin struct {
 	Name string
 	Fn   func(args ...Object) (Object, error)
+
+	// Invoking is used by Call instead of Fn when it's set, it gets the invoker running the call.
+	// Builtins calling back into fener or waiting on I/O use it to follow the current run.
+	Invoking func(invoker Invoker, args ...Object) (Object, error)
+
+	// Capability is needed from the environment to call the builtin, empty if none is needed.
+	Capability Capability
 }
 
 func (b *Builtin) Type() ObjectType { return BULITIN_OBJ }
@@ -102,23 +233,67 @@ func (r *Return) Pretty() string   { return r.Value.Pretty() }
 type Class struct {
 	Name    string
 	Methods map[string]*Function
+	// Statics are called on the class itself, without `this`.
+	Statics map[string]*Function
+	// Constants are evaluated once, when the class is defined.
+	Constants map[string]Object
+	// Fields lists the fields with a default in declaration order.
+	Fields []string
+	// Defaults are evaluated in Env for every new instance, before init is called.
+	Defaults map[string]ast.Expression
+	Env      *Environment
+	// Traits are the included traits, their methods are already merged into Methods.
+	Traits []*Trait
 }
 
 func (c *Class) Type() ObjectType { return CLASS_OBJ }
 func (c *Class) String() string   { return fmt.Sprintf("class %s", c.Name) }
 func (c *Class) Pretty() string   { return c.Name }
+func (c *Class) Get(key string) (Object, bool) {
+	if value, ok := c.Constants[key]; ok {
+		return value, true
+	}
+	if fn, ok := c.Statics[key]; ok {
+		return fn, true
+	}
+	return nil, false
+}
+
+type Trait struct {
+	Name    string
+	Methods map[string]*Function
+}
+
+func (t *Trait) Type() ObjectType { return TRAIT_OBJ }
+func (t *Trait) String() string   { return fmt.Sprintf("trait %s", t.Name) }
+func (t *Trait) Pretty() string   { return t.Name }
+
+// Implements reports whether the class includes trait.
+func (c *Class) Implements(trait *Trait) bool {
+	for _, included := range c.Traits {
+		if included == trait {
+			return true
+		}
+	}
+	return false
+}
 
 type Instance struct {
 	Class   *Class
 	Map     map[string]Object
 	Methods map[string]*Function
+	Frozen  bool
 }
 
 func (i *Instance) Type() ObjectType { return INSTANCE_OBJ }
-func (i *Instance) String() string   { return fmt.Sprintf("instance of %s", i.Class.Name) }
-func (i *Instance) Pretty() string   { return i.String() }
-func (i *Instance) Set(key string, value Object) {
+func (i *Instance) String() string   { return newPrinter(nil, false).print(i) }
+func (i *Instance) Pretty() string   { return newPrinter(nil, true).print(i) }
+func (i *Instance) Set(key string, value Object) error {
+	if i.Frozen {
+		return &FrozenError{Type: i.Type()}
+	}
 	i.Map[key] = value
+	return nil
 }
 func (i *Instance) Get(key string) (Object, bool) {
 	value, ok := i.Methods[key]
@@ -128,3 +303,35 @@ func (i *Instance) Get(key string) (Object, bool) {
 	}
 	return value, ok
 }
+
+type Module struct {
+	Name string
+	Path string
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) String() string   { return fmt.Sprintf("module %s", m.Name) }
+func (m *Module) Pretty() string   { return m.Name }
+
+// Get returns a member of the module, the builtins of its environment aren't members.
+func (m *Module) Get(key string) (Object, bool) {
+	value, ok := m.Env.Bindings[key]
+	if !ok || m.Env.isBuiltin(key, value) {
+		return nil, false
+	}
+	return value, true
+}
+
+// Names returns the names of the module's members, sorted.
+func (m *Module) Names() []string {
+	names := []string{}
+
+	for _, name := range sortedNames(m.Env.Bindings) {
+		if _, ok := m.Get(name); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
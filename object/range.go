@@ -0,0 +1,176 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// Range holds the integers from Start to End, End is left out when Exclusive.
+// The integers are only created when they are used.
+type Range struct {
+	Start     int64
+	End       int64
+	Exclusive bool
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) String() string   { return fmt.Sprintf("range(%s)", r.Pretty()) }
+func (r *Range) Pretty() string {
+	if r.Exclusive {
+		return fmt.Sprintf("%d...%d", r.Start, r.End)
+	}
+	return fmt.Sprintf("%d..%d", r.Start, r.End)
+}
+
+// NewRange creates a range, failing when it holds more integers than fit in an int.
+func NewRange(start, end int64, exclusive bool) (*Range, error) {
+	r := &Range{Start: start, End: end, Exclusive: exclusive}
+
+	if _, ok := r.length(); !ok {
+		return nil, fmt.Errorf("Range %s has too many elements", r.Pretty())
+	}
+
+	return r, nil
+}
+
+// Len is the number of integers in the range, a range ending before it starts is empty.
+// Ranges too long for NewRange are capped at math.MaxInt.
+func (r *Range) Len() int {
+	n, ok := r.length()
+	if !ok {
+		return math.MaxInt
+	}
+	return n
+}
+
+func (r *Range) length() (int, bool) {
+	end := r.End
+	if r.Exclusive {
+		if end == math.MinInt64 {
+			return 0, true
+		}
+		end--
+	}
+	if end < r.Start {
+		return 0, true
+	}
+
+	// The difference always fits in a uint64, even when it overflows an int64.
+	diff := uint64(end) - uint64(r.Start)
+	if diff >= math.MaxInt {
+		return 0, false
+	}
+	return int(diff) + 1, true
+}
+
+// At returns the integer at the 0-based index.
+func (r *Range) At(index int) Object {
+	return &Integer{Value: r.Start + int64(index)}
+}
+
+// Sequence is a value which can be walked element by element, like lists, strings and ranges.
+type Sequence interface {
+	Object
+	Len() int
+	At(index int) Object
+}
+
+// Position converts a 1-based index into a 0-based one, failing outside [1, length].
+func Position(index Object, length int) (int, error) {
+	i, ok := index.(*Integer)
+	if !ok {
+		return 0, fmt.Errorf("Index must be an integer, got %T", index)
+	}
+
+	if i.Value < 1 || i.Value > int64(length) {
+		return 0, fmt.Errorf("Index %d out of range [1, %d]", i.Value, length)
+	}
+
+	return int(i.Value) - 1, nil
+}
+
+// Index looks up a value in a map or a sequence, sequences are indexed from 1.
+// Missing map keys give null.
+func Index(left Object, index Object) (Object, error) {
+	switch left := left.(type) {
+	case *Map:
+		value, ok := left.Get(index)
+		if !ok {
+			return &Null{}, nil
+		}
+		return value, nil
+	case Sequence:
+		i, err := Position(index, left.Len())
+		if err != nil {
+			return nil, err
+		}
+		return left.At(i), nil
+	default:
+		return nil, fmt.Errorf("Can't index expression %T", left)
+	}
+}
+
+// Slice returns the elements of a sequence from start to end, both included.
+// Negative bounds count from the end, a nil or null bound leaves its side open
+// and bounds past either end are clamped.
+func Slice(obj Object, start Object, end Object) (Object, error) {
+	sequence, ok := obj.(Sequence)
+	if !ok {
+		return nil, fmt.Errorf("Can't slice expression %T", obj)
+	}
+
+	length := sequence.Len()
+
+	from, err := sliceBound(start, 1, length)
+	if err != nil {
+		return nil, err
+	}
+	to, err := sliceBound(end, length, length)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to = max(from, 1), min(to, length)
+	empty := from > to
+
+	switch sequence := sequence.(type) {
+	case *Array:
+		elements := []Object{}
+		if !empty {
+			elements = append(elements, sequence.Elements[from-1:to]...)
+		}
+		return &Array{Elements: elements}, nil
+	case *String:
+		if empty {
+			return &String{Value: ""}, nil
+		}
+		return &String{Value: sequence.Value[from-1 : to]}, nil
+	case *Range:
+		if empty {
+			return &Range{Start: sequence.Start, End: sequence.Start, Exclusive: true}, nil
+		}
+		return &Range{Start: sequence.Start + int64(from-1), End: sequence.Start + int64(to-1)}, nil
+	default:
+		return nil, fmt.Errorf("Can't slice expression %T", obj)
+	}
+}
+
+func sliceBound(index Object, open int, length int) (int, error) {
+	if index == nil || index.Type() == NULL_OBJ {
+		return open, nil
+	}
+
+	i, ok := index.(*Integer)
+	if !ok {
+		return 0, fmt.Errorf("Slice index must be an integer, got %T", index)
+	}
+
+	switch {
+	case i.Value == 0:
+		return 0, fmt.Errorf("Slice index can't be 0, indexes start at 1")
+	case i.Value < 0:
+		return length + int(i.Value) + 1, nil
+	default:
+		return int(i.Value), nil
+	}
+}
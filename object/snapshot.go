@@ -0,0 +1,239 @@
+package object
+
+import (
+	"fmt"
+)
+
+// SnapshotValue is the serializable form of a plain data value.
+type SnapshotValue struct {
+	Type     ObjectType                `json:"type"`
+	Int      int64                     `json:"int,omitempty"`
+	End      int64                     `json:"end,omitempty"`
+	Str      string                    `json:"str,omitempty"`
+	Bool     bool                      `json:"bool,omitempty"`
+	Elements []*SnapshotValue          `json:"elements,omitempty"`
+	Keys     []*SnapshotValue          `json:"keys,omitempty"`
+	Values   []*SnapshotValue          `json:"values,omitempty"`
+	Class    string                    `json:"class,omitempty"`
+	Fields   map[string]*SnapshotValue `json:"fields,omitempty"`
+	Frozen   bool                      `json:"frozen,omitempty"`
+}
+
+// Snapshot holds the plain data bindings of an environment.
+type Snapshot struct {
+	Bindings map[string]*SnapshotValue `json:"bindings"`
+	// Constants are the names of the bindings defined with const.
+	Constants []string `json:"constants,omitempty"`
+}
+
+// SnapshotError reports a binding that could not be snapshotted or restored.
+type SnapshotError struct {
+	Name   string
+	Reason string
+}
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Reason)
+}
+
+// TakeSnapshot captures the plain data bindings of env, builtins are skipped.
+// Functions, classes and modules can't be captured and are reported as errors.
+func TakeSnapshot(env *Environment) (*Snapshot, []error) {
+	snapshot := &Snapshot{Bindings: make(map[string]*SnapshotValue)}
+	errors := []error{}
+
+	for _, name := range sortedNames(env.Bindings) {
+		value := env.Bindings[name]
+
+		if _, ok := value.(*Builtin); ok {
+			continue
+		}
+
+		encoded, err := encodeSnapshot(value, map[Object]bool{})
+
+		if err != nil {
+			errors = append(errors, &SnapshotError{Name: name, Reason: err.Error()})
+			continue
+		}
+
+		snapshot.Bindings[name] = encoded
+
+		if env.constants[name] {
+			snapshot.Constants = append(snapshot.Constants, name)
+		}
+	}
+
+	return snapshot, errors
+}
+
+// Restore binds the snapshotted values in env, constants are bound as constants again.
+// Instances need their class to be defined in env already, constants of env aren't overwritten.
+func (s *Snapshot) Restore(env *Environment) []error {
+	errors := []error{}
+
+	constants := map[string]bool{}
+	for _, name := range s.Constants {
+		constants[name] = true
+	}
+
+	for _, name := range sortedNames(s.Bindings) {
+		value, err := decodeSnapshot(s.Bindings[name], env)
+
+		if err == nil {
+			if constants[name] {
+				err = env.DefineConst(name, value)
+			} else {
+				err = env.Define(name, value)
+			}
+		}
+
+		if err != nil {
+			errors = append(errors, &SnapshotError{Name: name, Reason: err.Error()})
+		}
+	}
+
+	return errors
+}
+
+func encodeSnapshot(obj Object, seen map[Object]bool) (*SnapshotValue, error) {
+	if seen[obj] {
+		return nil, fmt.Errorf("cyclic %s values can't be snapshotted", obj.Type())
+	}
+
+	switch obj := obj.(type) {
+	case *Integer:
+		return &SnapshotValue{Type: INTEGER_OBJ, Int: obj.Value}, nil
+	case *String:
+		return &SnapshotValue{Type: STRING_OBJ, Str: obj.Value}, nil
+	case *Boolean:
+		return &SnapshotValue{Type: BOOLEAN_OBJ, Bool: obj.Value}, nil
+	case *Null:
+		return &SnapshotValue{Type: NULL_OBJ}, nil
+	case *Range:
+		return &SnapshotValue{Type: RANGE_OBJ, Int: obj.Start, End: obj.End, Bool: obj.Exclusive}, nil
+	case *Array:
+		seen[obj] = true
+		defer delete(seen, obj)
+
+		value := &SnapshotValue{Type: ARRAY_OBJ, Frozen: obj.Frozen}
+
+		for _, element := range obj.Elements {
+			encoded, err := encodeSnapshot(element, seen)
+			if err != nil {
+				return nil, err
+			}
+			value.Elements = append(value.Elements, encoded)
+		}
+
+		return value, nil
+	case *Map:
+		seen[obj] = true
+		defer delete(seen, obj)
+
+		value := &SnapshotValue{Type: MAP_OBJ, Frozen: obj.Frozen}
+
+		for _, pair := range obj.Items() {
+			key, err := encodeSnapshot(pair.Key, seen)
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := encodeSnapshot(pair.Value, seen)
+			if err != nil {
+				return nil, err
+			}
+			value.Keys = append(value.Keys, key)
+			value.Values = append(value.Values, encoded)
+		}
+
+		return value, nil
+	case *Instance:
+		seen[obj] = true
+		defer delete(seen, obj)
+
+		value := &SnapshotValue{Type: INSTANCE_OBJ, Class: obj.Class.Name, Fields: make(map[string]*SnapshotValue), Frozen: obj.Frozen}
+
+		for name, field := range obj.Map {
+			encoded, err := encodeSnapshot(field, seen)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+			value.Fields[name] = encoded
+		}
+
+		return value, nil
+	default:
+		return nil, fmt.Errorf("%s values can't be snapshotted", obj.Type())
+	}
+}
+
+func decodeSnapshot(value *SnapshotValue, env *Environment) (Object, error) {
+	switch value.Type {
+	case INTEGER_OBJ:
+		return &Integer{Value: value.Int}, nil
+	case STRING_OBJ:
+		return &String{Value: value.Str}, nil
+	case BOOLEAN_OBJ:
+		return &Boolean{Value: value.Bool}, nil
+	case NULL_OBJ:
+		return &Null{}, nil
+	case RANGE_OBJ:
+		return NewRange(value.Int, value.End, value.Bool)
+	case ARRAY_OBJ:
+		array := &Array{Elements: []Object{}}
+
+		for _, element := range value.Elements {
+			decoded, err := decodeSnapshot(element, env)
+			if err != nil {
+				return nil, err
+			}
+			array.Elements = append(array.Elements, decoded)
+		}
+		array.Frozen = value.Frozen
+
+		return array, nil
+	case MAP_OBJ:
+		if len(value.Keys) != len(value.Values) {
+			return nil, fmt.Errorf("map has %d keys but %d values", len(value.Keys), len(value.Values))
+		}
+
+		m := NewMap()
+
+		for i := range value.Keys {
+			key, err := decodeSnapshot(value.Keys[i], env)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := decodeSnapshot(value.Values[i], env)
+			if err != nil {
+				return nil, err
+			}
+			if err := m.Set(key, decoded); err != nil {
+				return nil, err
+			}
+		}
+		m.Frozen = value.Frozen
+
+		return m, nil
+	case INSTANCE_OBJ:
+		class, ok := env.Get(value.Class).(*Class)
+
+		if !ok {
+			return nil, fmt.Errorf("class %s is not defined", value.Class)
+		}
+
+		instance := &Instance{Class: class, Map: make(map[string]Object), Methods: class.Methods}
+
+		for name, field := range value.Fields {
+			decoded, err := decodeSnapshot(field, env)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+			instance.Map[name] = decoded
+		}
+		instance.Frozen = value.Frozen
+
+		return instance, nil
+	default:
+		return nil, fmt.Errorf("unknown snapshot type %q", value.Type)
+	}
+}
@@ -0,0 +1,93 @@
+package object
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	class := &Class{Name: "Point", Methods: map[string]*Function{}}
+
+	m := NewMap()
+	m.Set(&String{Value: "name"}, &String{Value: "fener"})
+	m.Set(&Integer{Value: 1}, &Boolean{Value: true})
+
+	cyclic := &Array{}
+	cyclic.Elements = []Object{cyclic}
+
+	env := NewEnvironment()
+	env.Set("n", &Integer{Value: 42})
+	env.Set("xs", &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "two"}}})
+	env.Set("m", m)
+	env.Set("r", &Range{Start: 1, End: 10, Exclusive: true})
+	env.Set("Point", class)
+	env.Set("p", &Instance{Class: class, Map: map[string]Object{"x": &Integer{Value: 3}}, Methods: class.Methods})
+	env.Set("f", &Function{})
+	env.Set("cyclic", cyclic)
+	env.DefineConst("c", &Integer{Value: 7})
+	env.Set("frozen", Freeze(&Array{Elements: []Object{NewMap()}}))
+
+	snapshot, errors := TakeSnapshot(env)
+
+	expectedErrors := []string{
+		"Point: CLASS values can't be snapshotted",
+		"cyclic: cyclic ARRAY values can't be snapshotted",
+		"f: FUNCTION values can't be snapshotted",
+	}
+
+	if len(errors) != len(expectedErrors) {
+		t.Fatalf("Expected %d errors, got %v", len(expectedErrors), errors)
+	}
+
+	for i, err := range errors {
+		if err.Error() != expectedErrors[i] {
+			t.Errorf("Expected error %q, got %q", expectedErrors[i], err.Error())
+		}
+	}
+
+	contents, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := &Snapshot{}
+	if err := json.Unmarshal(contents, decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	restored := NewEnvironment()
+	restored.Set("Point", class)
+
+	if errors := decoded.Restore(restored); len(errors) != 0 {
+		t.Fatalf("Unexpected errors: %v", errors)
+	}
+
+	for _, name := range []string{"n", "xs", "m", "r", "c", "frozen"} {
+		if restored.Get(name).String() != env.Get(name).String() {
+			t.Errorf("Expected %s to be %s, got %s", name, env.Get(name), restored.Get(name))
+		}
+	}
+
+	// Constants stay read-only and frozen values stay frozen.
+	if err := restored.Define("c", &Integer{Value: 8}); err == nil {
+		t.Errorf("Expected c to be restored as a constant")
+	}
+
+	frozen := restored.Get("frozen").(*Array)
+	if !frozen.Frozen || !frozen.Elements[0].(*Map).Frozen {
+		t.Errorf("Expected frozen to be restored frozen, got %v", frozen)
+	}
+
+	if restored.IsConst("n") {
+		t.Errorf("Expected n to be restored as a variable")
+	}
+
+	p, ok := restored.Get("p").(*Instance)
+	if !ok || p.Class != class || p.Map["x"].String() != "int(3)" {
+		t.Errorf("Expected instance of Point with x = 3, got %v", restored.Get("p"))
+	}
+
+	if errors := decoded.Restore(NewEnvironment()); len(errors) != 1 || errors[0].Error() != "p: class Point is not defined" {
+		t.Errorf("Expected missing class error, got %v", errors)
+	}
+}
@@ -0,0 +1,122 @@
+package object
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// The builtins in this file reach outside the interpreter, each is guarded by a capability.
+
+func readFileFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for READ_FILE. got=%d, want=1", len(args))
+	}
+	path, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: string(contents)}, nil
+}
+func writeFileFunc(args ...Object) (Object, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("wrong number of arguments for WRITE_FILE. got=%d, want=2", len(args))
+	}
+	path, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	contents, err := toString(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		return nil, err
+	}
+	return &Null{}, nil
+}
+func getenvFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for GETENV. got=%d, want=1", len(args))
+	}
+	name, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: os.Getenv(name)}, nil
+}
+func execFunc(args ...Object) (Object, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("wrong number of arguments for EXEC. got=%d, want at least 1", len(args))
+	}
+	command := []string{}
+	for _, arg := range args {
+		str, err := toString(arg)
+		if err != nil {
+			return nil, err
+		}
+		command = append(command, str)
+	}
+	output, err := exec.Command(command[0], command[1:]...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: string(output)}, nil
+}
+
+// fetchTimeout bounds a whole request, so a server that never answers can't hang the program.
+const fetchTimeout = 30 * time.Second
+
+var fetchClient = &http.Client{Timeout: fetchTimeout}
+
+func fetchFunc(ctx context.Context, args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for FETCH. got=%d, want=1", len(args))
+	}
+	url, err := toString(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := fetchClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: string(body)}, nil
+}
+func clockFunc(args ...Object) (Object, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("wrong number of arguments for CLOCK. got=%d, want=0", len(args))
+	}
+	return &Integer{Value: time.Now().UnixMilli()}, nil
+}
+func randomFunc(args ...Object) (Object, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments for RANDOM. got=%d, want=1", len(args))
+	}
+	max, ok := args[0].(*Integer)
+	if !ok || max.Value < 1 {
+		return nil, fmt.Errorf("argument to RANDOM should be a positive 'integer', got %s", args[0].Pretty())
+	}
+	return &Integer{Value: rand.Int63n(max.Value) + 1}, nil
+}
@@ -1,9 +1,11 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/lexer"
 	"github.com/pspiagicw/fener/token"
 )
 
@@ -32,3 +34,126 @@ func TestParserAssignment(t *testing.T) {
 
 	checkTree(t, input, expectedTree)
 }
+
+func TestParserLet(t *testing.T) {
+	input := `let a = 1`
+
+	expectedTree := []ast.Statement{
+		&ast.LetStatement{
+			Token:  &token.Token{Type: token.LET, Value: "let"},
+			Target: &ast.Identifier{Value: "a", Token: &token.Token{Type: token.IDENT, Value: "a"}},
+			Value:  &ast.Integer{Value: 1, Token: &token.Token{Type: token.INT, Value: "1"}},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
+
+func TestParserConst(t *testing.T) {
+	input := `const a = 1`
+
+	expectedTree := []ast.Statement{
+		&ast.ConstStatement{
+			Token:  &token.Token{Type: token.CONST, Value: "const"},
+			Target: &ast.Identifier{Value: "a", Token: &token.Token{Type: token.IDENT, Value: "a"}},
+			Value:  &ast.Integer{Value: 1, Token: &token.Token{Type: token.INT, Value: "1"}},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
+
+func TestParserMultipleAssignment(t *testing.T) {
+	input := `a, b = b, a`
+
+	expectedTree := []ast.Statement{
+		&ast.ExpressionStatement{
+			Token: &token.Token{Type: token.IDENT, Value: "a"},
+			Expression: &ast.AssignmentExpression{
+				Token: &token.Token{Type: token.ASSIGN, Value: "="},
+				Target: &ast.Array{
+					Token: &token.Token{Type: token.IDENT, Value: "a"},
+					Elements: []ast.Expression{
+						&ast.Identifier{Value: "a", Token: &token.Token{Type: token.IDENT, Value: "a"}},
+						&ast.Identifier{Value: "b", Token: &token.Token{Type: token.IDENT, Value: "b"}},
+					},
+				},
+				Value: &ast.Array{
+					Token: &token.Token{Type: token.IDENT, Value: "b"},
+					Elements: []ast.Expression{
+						&ast.Identifier{Value: "b", Token: &token.Token{Type: token.IDENT, Value: "b"}},
+						&ast.Identifier{Value: "a", Token: &token.Token{Type: token.IDENT, Value: "a"}},
+					},
+				},
+			},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
+
+func TestParserLineStartingDestructuring(t *testing.T) {
+	tt := []struct {
+		input    string
+		expected []string
+	}{
+		{"pair = [1, 2]\n[x, y] = pair", []string{"pair = [1, 2]", "[x, y] = pair"}},
+		{"f(1)\n[a, [b, c]] = pair", []string{"f(1)", "[a, [b, c]] = pair"}},
+		{"xs\n[0]", []string{"(xs[0])"}},
+		{"xs\n[0] == 1", []string{"((xs[0]) == 1)"}},
+		{"xs[0] = 1", []string{"(xs[0]) = 1"}},
+	}
+
+	for _, tc := range tt {
+		p := New(lexer.New(tc.input))
+		program := p.Parse()
+
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parsing %q failed: %v", tc.input, p.Errors())
+		}
+
+		got := []string{}
+		for _, stmt := range program.Statements {
+			got = append(got, stmt.String())
+		}
+
+		if strings.Join(got, "\n") != strings.Join(tc.expected, "\n") {
+			t.Errorf("Expected %q to parse as %q, got %q", tc.input, tc.expected, got)
+		}
+	}
+}
+
+func TestParserCompoundAssignment(t *testing.T) {
+	input := `xs[i] -= 1 + 2`
+
+	expectedTree := []ast.Statement{
+		&ast.ExpressionStatement{
+			Token: &token.Token{Type: token.IDENT, Value: "xs"},
+			Expression: &ast.CompoundAssignment{
+				Token: &token.Token{Type: token.MINUS_ASSIGN, Value: "-="},
+				Target: &ast.IndexExpression{
+					Token: &token.Token{Type: token.LSQUARE, Value: "["},
+					Left:  &ast.Identifier{Value: "xs", Token: &token.Token{Type: token.IDENT, Value: "xs"}},
+					Index: &ast.Identifier{Value: "i", Token: &token.Token{Type: token.IDENT, Value: "i"}},
+				},
+				Operator: token.MINUS,
+				Value: &ast.InfixExpression{
+					Left:     &ast.Integer{Value: 1, Token: &token.Token{Type: token.INT, Value: "1"}},
+					Operator: token.PLUS,
+					Right:    &ast.Integer{Value: 2, Token: &token.Token{Type: token.INT, Value: "2"}},
+				},
+			},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+
+	for _, input := range []string{`1 += 2`, `a?.b *= 2`, `[a, b] += 1`} {
+		p := New(lexer.New(input))
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("Expected parse errors for %q", input)
+		}
+	}
+}
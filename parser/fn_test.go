@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/lexer"
 	"github.com/pspiagicw/fener/token"
 )
 
@@ -137,3 +138,103 @@ func TestParserFunction(t *testing.T) {
 	checkTree(t, input, expectedTree)
 
 }
+
+func TestParserParameters(t *testing.T) {
+	input := `fn f(a, b = 1, ...rest) end`
+
+	expectedTree := []ast.Statement{
+		&ast.FunctionStatement{
+			Token:  &token.Token{Type: token.FUNCTION, Value: "fn"},
+			Target: &ast.Identifier{Token: &token.Token{Type: token.IDENT, Value: "f"}, Value: "f"},
+			Arguments: []*ast.Identifier{
+				{Token: &token.Token{Type: token.IDENT, Value: "a"}, Value: "a"},
+				{Token: &token.Token{Type: token.IDENT, Value: "b"}, Value: "b"},
+			},
+			Defaults: map[string]ast.Expression{
+				"b": &ast.Integer{Token: &token.Token{Type: token.INT, Value: "1"}, Value: 1},
+			},
+			Rest: &ast.Identifier{Token: &token.Token{Type: token.IDENT, Value: "rest"}, Value: "rest"},
+			Body: &ast.BlockStatement{Statements: []ast.Statement{}},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
+
+func TestParserPatternParameters(t *testing.T) {
+	input := `fn f([x, y], {"k" = v}) end`
+
+	point := &ast.Array{
+		Token: &token.Token{Type: token.LSQUARE, Value: "["},
+		Elements: []ast.Expression{
+			&ast.Identifier{Token: &token.Token{Type: token.IDENT, Value: "x"}, Value: "x"},
+			&ast.Identifier{Token: &token.Token{Type: token.IDENT, Value: "y"}, Value: "y"},
+		},
+	}
+	options := &ast.Map{
+		Token:  &token.Token{Type: token.LBRACE, Value: "{"},
+		Keys:   []ast.Expression{&ast.String{Token: &token.Token{Type: token.STRING, Value: "k"}, Value: "k"}},
+		Values: []ast.Expression{&ast.Identifier{Token: &token.Token{Type: token.IDENT, Value: "v"}, Value: "v"}},
+	}
+
+	expectedTree := []ast.Statement{
+		&ast.FunctionStatement{
+			Token:  &token.Token{Type: token.FUNCTION, Value: "fn"},
+			Target: &ast.Identifier{Token: &token.Token{Type: token.IDENT, Value: "f"}, Value: "f"},
+			Arguments: []*ast.Identifier{
+				{Token: &token.Token{Type: token.LSQUARE, Value: "["}, Value: "[x, y]"},
+				{Token: &token.Token{Type: token.LBRACE, Value: "{"}, Value: `{"k" = v}`},
+			},
+			Patterns: map[string]ast.Expression{
+				"[x, y]":    point,
+				`{"k" = v}`: options,
+			},
+			Body: &ast.BlockStatement{Statements: []ast.Statement{}},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
+
+func TestParserKeywordArguments(t *testing.T) {
+	input := `f(1, name: "x")`
+
+	expectedTree := []ast.Statement{
+		&ast.ExpressionStatement{
+			Token: &token.Token{Type: token.IDENT, Value: "f"},
+			Expression: &ast.CallExpression{
+				Token:    &token.Token{Type: token.LPAREN, Value: "("},
+				Function: &ast.Identifier{Token: &token.Token{Type: token.IDENT, Value: "f"}, Value: "f"},
+				Arguments: []ast.Expression{
+					&ast.Integer{Token: &token.Token{Type: token.INT, Value: "1"}, Value: 1},
+					&ast.KeywordArgument{
+						Token: &token.Token{Type: token.IDENT, Value: "name"},
+						Key:   "name",
+						Value: &ast.String{Token: &token.Token{Type: token.STRING, Value: "x"}, Value: "x"},
+					},
+				},
+			},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
+
+func TestParserParameterErrors(t *testing.T) {
+	table := []string{
+		`fn f(a = 1, b) end`,
+		`fn f(...xs, a) end`,
+		`f(name: 1, 2)`,
+		`fn f([x, 1]) end`,
+		`a, 1 = 1, 2`,
+	}
+
+	for _, input := range table {
+		p := New(lexer.New(input))
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("Expected parse errors for %q", input)
+		}
+	}
+}
@@ -166,3 +166,48 @@ func TestParserIf(t *testing.T) {
 
 	checkTree(t, input, expectedTree)
 }
+
+func TestParserMatch(t *testing.T) {
+	input := `match x case 1 then 2 case y if y then 3 end`
+
+	expectedTree := []ast.Statement{
+		&ast.ExpressionStatement{
+			Token: &token.Token{Type: token.MATCH, Value: "match"},
+			Expression: &ast.MatchExpression{
+				Token: &token.Token{Type: token.MATCH, Value: "match"},
+				Value: &ast.Identifier{Value: "x", Token: &token.Token{Type: token.IDENT, Value: "x"}},
+				Cases: []*ast.MatchCase{
+					{
+						Token:   &token.Token{Type: token.CASE, Value: "case"},
+						Pattern: &ast.Integer{Value: 1, Token: &token.Token{Type: token.INT, Value: "1"}},
+						Body: &ast.BlockStatement{
+							Token: &token.Token{Type: token.INT, Value: "2"},
+							Statements: []ast.Statement{
+								&ast.ExpressionStatement{
+									Token:      &token.Token{Type: token.INT, Value: "2"},
+									Expression: &ast.Integer{Value: 2, Token: &token.Token{Type: token.INT, Value: "2"}},
+								},
+							},
+						},
+					},
+					{
+						Token:   &token.Token{Type: token.CASE, Value: "case"},
+						Pattern: &ast.Identifier{Value: "y", Token: &token.Token{Type: token.IDENT, Value: "y"}},
+						Guard:   &ast.Identifier{Value: "y", Token: &token.Token{Type: token.IDENT, Value: "y"}},
+						Body: &ast.BlockStatement{
+							Token: &token.Token{Type: token.INT, Value: "3"},
+							Statements: []ast.Statement{
+								&ast.ExpressionStatement{
+									Token:      &token.Token{Type: token.INT, Value: "3"},
+									Expression: &ast.Integer{Value: 3, Token: &token.Token{Type: token.INT, Value: "3"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
@@ -7,8 +7,9 @@ import (
 
 func (p *Parser) parseFieldExpression(left ast.Expression) ast.Expression {
 	expression := &ast.FieldExpression{
-		Token:  p.curToken,
-		Target: left,
+		Token:    p.curToken,
+		Target:   left,
+		Optional: p.curTokenIs(token.OPTIONAL_DOT),
 	}
 
 	p.advance()
@@ -21,20 +22,54 @@ func (p *Parser) parseFieldExpression(left ast.Expression) ast.Expression {
 
 	return expression
 }
+
+// parseIndexExpression parses `left[index]` and the slices `left[start:end]`, where either bound can be left out.
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	expression := &ast.IndexExpression{
-		Token: p.curToken,
-		Left:  left,
+	start := p.curToken
+
+	p.advance()
+
+	var index ast.Expression
+	if !p.curTokenIs(token.COLON) {
+		index = p.parseExpression(LOWEST)
+	}
+
+	if !p.curTokenIs(token.COLON) {
+		if !p.expect(token.RSQUARE) {
+			return nil
+		}
+		return &ast.IndexExpression{Token: start, Left: left, Index: index}
 	}
 
 	p.advance()
 
-	expression.Index = p.parseExpression(LOWEST)
+	slice := &ast.SliceExpression{Token: start, Left: left, Start: index}
+
+	if !p.curTokenIs(token.RSQUARE) {
+		slice.End = p.parseExpression(LOWEST)
+	}
 
 	if !p.expect(token.RSQUARE) {
 		return nil
 	}
 
+	return slice
+}
+
+// parsePipeExpression parses `left |> right`, right is a call or an expression giving a callable.
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	expression := &ast.PipeExpression{
+		Token: p.curToken,
+		Left:  left,
+	}
+
+	p.advance()
+
+	expression.Right = p.parseExpression(PIPE)
+	if expression.Right == nil {
+		return nil
+	}
+
 	return expression
 }
 func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
@@ -45,10 +80,163 @@ func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 
 	p.advance()
 
-	expression.Arguments = p.parseExpressionList(token.RPAREN)
+	expression.Arguments = p.parseCallArguments()
 
 	return expression
 }
+
+// parseCallArguments parses positional arguments followed by `name: value` keyword arguments.
+func (p *Parser) parseCallArguments() []ast.Expression {
+	arguments := []ast.Expression{}
+	keywords := false
+
+	if p.curTokenIs(token.RPAREN) {
+		p.advance()
+		return arguments
+	}
+
+	for true {
+		if p.curTokenIs(token.IDENT) && p.peektokenIs(token.COLON) {
+			keyword := &ast.KeywordArgument{Token: p.curToken, Key: p.curToken.Value}
+
+			p.advance()
+			p.advance()
+
+			keyword.Value = p.parseExpression(LOWEST)
+			arguments = append(arguments, keyword)
+			keywords = true
+		} else {
+			argument := p.parseExpression(LOWEST)
+
+			if keywords {
+				p.addError("Positional argument follows keyword arguments")
+				return arguments
+			}
+
+			if argument != nil {
+				arguments = append(arguments, argument)
+			}
+		}
+
+		if p.curTokenIs(token.RPAREN) {
+			p.advance()
+			break
+		}
+		if !p.expect(token.COMMA) {
+			return arguments
+		}
+	}
+
+	return arguments
+}
+
+// checkTarget reports whether target can be assigned, lists and maps destructure the value.
+func (p *Parser) checkTarget(target ast.Expression) bool {
+	switch target := target.(type) {
+	case *ast.Identifier:
+	case *ast.FieldExpression:
+		if target.Optional {
+			p.addError("Can't assign to optional field %s", target.String())
+			return false
+		}
+	case *ast.IndexExpression:
+	case *ast.Array:
+		for _, element := range target.Elements {
+			if !p.checkTarget(element) {
+				return false
+			}
+		}
+	case *ast.Map:
+		for _, value := range target.Values {
+			if !p.checkTarget(value) {
+				return false
+			}
+		}
+	default:
+		p.addError("Invalid assignment target %T", target)
+		return false
+	}
+	return true
+}
+
+// compoundOperators maps the compound assignments to the operator they apply.
+var compoundOperators = map[token.TokenType]token.TokenType{
+	token.PLUS_ASSIGN:     token.PLUS,
+	token.MINUS_ASSIGN:    token.MINUS,
+	token.MULTIPLY_ASSIGN: token.MULTIPLY,
+	token.DIVIDE_ASSIGN:   token.DIVIDE,
+	token.MOD_ASSIGN:      token.MOD,
+}
+
+// parseCompoundAssignment parses `target += value`, only names, fields and indexes can be targets.
+func (p *Parser) parseCompoundAssignment(left ast.Expression) ast.Expression {
+	switch target := left.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	case *ast.FieldExpression:
+		if target.Optional {
+			p.addError("Can't assign to optional field %s", target.String())
+			return nil
+		}
+	default:
+		p.addError("Invalid compound assignment target %s", left.String())
+		return nil
+	}
+
+	assignment := &ast.CompoundAssignment{
+		Token:    p.curToken,
+		Target:   left,
+		Operator: compoundOperators[p.curToken.Type],
+	}
+
+	p.advance()
+
+	assignment.Value = p.parseExpression(LOWEST)
+
+	return assignment
+}
+
+// parseMultipleAssignment parses `a, b = b, a` into an assignment to a list target.
+// The first target has already been parsed.
+func (p *Parser) parseMultipleAssignment(start *token.Token, first ast.Expression) ast.Expression {
+	targets := &ast.Array{Token: start, Elements: []ast.Expression{first}}
+
+	for p.curTokenIs(token.COMMA) {
+		p.advance()
+
+		expression := p.parseExpression(LOWEST)
+
+		// The last target is parsed along with the `=` and the first value.
+		assignment, ok := expression.(*ast.AssignmentExpression)
+		if !ok {
+			targets.Elements = append(targets.Elements, expression)
+			continue
+		}
+
+		targets.Elements = append(targets.Elements, assignment.Target)
+
+		values := []ast.Expression{assignment.Value}
+		for p.curTokenIs(token.COMMA) {
+			p.advance()
+			values = append(values, p.parseExpression(LOWEST))
+		}
+
+		if len(values) > 1 {
+			assignment.Value = &ast.Array{Token: assignment.Token, Elements: values}
+		}
+
+		if !p.checkTarget(targets) {
+			return nil
+		}
+
+		assignment.Target = targets
+
+		return assignment
+	}
+
+	p.addError("Expected = after assignment targets, got %s", p.curToken.Type)
+	return nil
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	expressions := []ast.Expression{}
 
@@ -87,14 +275,8 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
-
-	switch left.(type) {
-	case *ast.Identifier:
-	case *ast.FieldExpression:
-	default:
-		p.addError("Invalid assignment target %T", left)
+	if !p.checkTarget(left) {
 		return nil
-
 	}
 
 	p.advance()
@@ -41,6 +41,23 @@ func TestFieldExpression(t *testing.T) {
 	checkTree(t, input, expectedTree)
 }
 
+func TestOptionalFieldExpression(t *testing.T) {
+	input := `a?.b`
+
+	expectedTree := []ast.Statement{
+		&ast.ExpressionStatement{
+			Expression: &ast.FieldExpression{
+				Token:    &token.Token{Type: token.OPTIONAL_DOT, Value: "?."},
+				Target:   &ast.Identifier{Value: "a", Token: &token.Token{Type: token.IDENT, Value: "a"}},
+				Field:    &token.Token{Type: token.IDENT, Value: "b"},
+				Optional: true,
+			},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
+
 func TestIndexExpression(t *testing.T) {
 	input := `myArray[1 + 1]`
 
@@ -61,6 +78,20 @@ func TestIndexExpression(t *testing.T) {
 
 }
 
+func TestSliceExpression(t *testing.T) {
+	input := `xs[1:]`
+
+	expectedTree := []ast.Statement{
+		&ast.ExpressionStatement{
+			Expression: &ast.SliceExpression{
+				Token: &token.Token{Type: token.LSQUARE, Value: "["},
+				Left:  &ast.Identifier{Value: "xs", Token: &token.Token{Type: token.IDENT, Value: "xs"}},
+				Start: &ast.Integer{Value: 1, Token: &token.Token{Type: token.INT, Value: "1"}},
+			},
+		},
+	}
+	checkTree(t, input, expectedTree)
+}
 func TestBooleanParser(t *testing.T) {
 	input := `
     true && true
@@ -263,6 +294,30 @@ func TestParserInfixTable(t *testing.T) {
 		input    string
 		expected string
 	}{
+		{
+			"a < 1..n + 1",
+			"(a < (1 .. (n + 1)))",
+		},
+		{
+			"xs[1...len(xs)]",
+			"(xs[(1 ... len(xs))])",
+		},
+		{
+			"xs[:-1] == xs[a + 1:]",
+			"((xs[:(-1)]) == (xs[(a + 1):]))",
+		},
+		{
+			"a + b |> f(c) |> g",
+			"(((a + b) |> f(c)) |> g)",
+		},
+		{
+			"x |> f == 1..3 |> len()",
+			"((x |> f) == ((1 .. 3) |> len()))",
+		},
+		{
+			"a ?? b || c == null",
+			"(a ?? (b OR (c == null)))",
+		},
 		{
 			"5 < 4 != 3 > 4",
 			"((5 < 4) != (3 > 4))",
@@ -181,3 +181,29 @@ func checkTree(t *testing.T, input string, expectedTree []ast.Statement) {
 	}
 
 }
+
+func TestMapParser(t *testing.T) {
+	input := `{"name" = "fener", "age" = 1 + 2}`
+
+	expectedTree := []ast.Statement{
+		&ast.ExpressionStatement{
+			Expression: &ast.Map{
+				Keys: []ast.Expression{
+					&ast.String{Value: "name", Token: &token.Token{Type: token.STRING, Value: "name", Line: 0}},
+					&ast.String{Value: "age", Token: &token.Token{Type: token.STRING, Value: "age", Line: 0}},
+				},
+				Values: []ast.Expression{
+					&ast.String{Value: "fener", Token: &token.Token{Type: token.STRING, Value: "fener", Line: 0}},
+					&ast.InfixExpression{
+						Left:     &ast.Integer{Value: 1, Token: &token.Token{Type: token.INT, Value: "1", Line: 0}},
+						Operator: token.PLUS,
+						Right:    &ast.Integer{Value: 2, Token: &token.Token{Type: token.INT, Value: "2", Line: 0}},
+					},
+				},
+				Token: &token.Token{Type: token.LBRACE, Value: "{", Line: 0},
+			},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
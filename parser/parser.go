@@ -11,9 +11,12 @@ import (
 const (
 	_ = iota
 	LOWEST
+	COALESCE
 	BOOLEAN
 	EQUALS
 	COMPARE
+	PIPE
+	RANGE
 	ADDITION
 	MULTIPLY
 	MOD
@@ -25,49 +28,76 @@ const (
 )
 
 var precedences = map[token.TokenType]int{
-	token.ASSIGN:   ASSIGNMENT,
-	token.PLUS:     ADDITION,
-	token.MINUS:    ADDITION,
-	token.MULTIPLY: MULTIPLY,
-	token.DIVIDE:   MULTIPLY,
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       COMPARE,
-	token.GT:       COMPARE,
-	token.LTE:      COMPARE,
-	token.GTE:      COMPARE,
-	token.MOD:      MOD,
-	token.LPAREN:   CALL,
-	token.AND:      BOOLEAN,
-	token.OR:       BOOLEAN,
-	token.BITAND:   BOOLEAN,
-	token.BITOR:    BOOLEAN,
-	token.LSQUARE:  INDEX,
-	token.DOT:      FIELD,
+	token.ASSIGN:          ASSIGNMENT,
+	token.PLUS_ASSIGN:     ASSIGNMENT,
+	token.MINUS_ASSIGN:    ASSIGNMENT,
+	token.MULTIPLY_ASSIGN: ASSIGNMENT,
+	token.DIVIDE_ASSIGN:   ASSIGNMENT,
+	token.MOD_ASSIGN:      ASSIGNMENT,
+	token.PLUS:            ADDITION,
+	token.MINUS:           ADDITION,
+	token.MULTIPLY:        MULTIPLY,
+	token.DIVIDE:          MULTIPLY,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              COMPARE,
+	token.GT:              COMPARE,
+	token.LTE:             COMPARE,
+	token.GTE:             COMPARE,
+	token.MOD:             MOD,
+	token.LPAREN:          CALL,
+	token.AND:             BOOLEAN,
+	token.OR:              BOOLEAN,
+	token.BITAND:          BOOLEAN,
+	token.BITOR:           BOOLEAN,
+	token.LSQUARE:         INDEX,
+	token.DOT:             FIELD,
+	token.OPTIONAL_DOT:    FIELD,
+	token.COALESCE:        COALESCE,
+	token.RANGE:           RANGE,
+	token.PIPE:            PIPE,
+	token.ELLIPSIS:        RANGE,
 }
 
 type infixParseFn func(ast.Expression) ast.Expression
 type prefixParseFn func() ast.Expression
 
+// Diagnostic is a parser error along with the line it was found on.
+type Diagnostic struct {
+	Line    int
+	Message string
+}
+
 type Parser struct {
-	l      *lexer.Lexer
-	errors []string
+	l           *lexer.Lexer
+	errors      []string
+	diagnostics []Diagnostic
 
+	prevToken *token.Token
 	curToken  *token.Token
 	peekToken *token.Token
+	// buffered holds the tokens after peekToken read by lookahead.
+	buffered []*token.Token
 
 	infixParseFns  map[token.TokenType]infixParseFn
 	prefixParseFns map[token.TokenType]prefixParseFn
 }
 
 func (p *Parser) addError(message string, args ...interface{}) {
-	p.errors = append(p.errors, fmt.Sprintf(message, args...))
+	message = fmt.Sprintf(message, args...)
+	p.errors = append(p.errors, message)
+	p.diagnostics = append(p.diagnostics, Diagnostic{Line: p.curToken.Line, Message: message})
 }
 
 func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+// Diagnostics returns the errors with their lines.
+func (p *Parser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{l: l}
 
@@ -77,11 +107,14 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.STRING, p.parseString)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.NULL, p.parseNull)
 	p.registerPrefix(token.IDENT, p.parseIdent)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	p.registerPrefix(token.FUNCTION, p.parseLambda)
 	p.registerPrefix(token.LSQUARE, p.parseArray)
+	p.registerPrefix(token.LBRACE, p.parseMap)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 
@@ -99,6 +132,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LTE, p.parseInfixExpression)
 	p.registerInfix(token.GTE, p.parseInfixExpression)
 	p.registerInfix(token.ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseCompoundAssignment)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseCompoundAssignment)
+	p.registerInfix(token.MULTIPLY_ASSIGN, p.parseCompoundAssignment)
+	p.registerInfix(token.DIVIDE_ASSIGN, p.parseCompoundAssignment)
+	p.registerInfix(token.MOD_ASSIGN, p.parseCompoundAssignment)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.AND, p.parseInfixExpression)
 	p.registerInfix(token.OR, p.parseInfixExpression)
@@ -106,6 +144,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.BITOR, p.parseInfixExpression)
 	p.registerInfix(token.LSQUARE, p.parseIndexExpression)
 	p.registerInfix(token.DOT, p.parseFieldExpression)
+	p.registerInfix(token.OPTIONAL_DOT, p.parseFieldExpression)
+	p.registerInfix(token.COALESCE, p.parseInfixExpression)
+	p.registerInfix(token.RANGE, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
+	p.registerInfix(token.ELLIPSIS, p.parseInfixExpression)
 
 	p.advance()
 	p.advance()
@@ -149,6 +192,14 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseTestStatement()
 	case token.CLASS:
 		return p.parseClassStatement()
+	case token.TRAIT:
+		return p.parseTraitStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.LET:
+		return p.parseLetStatement()
+	case token.CONST:
+		return p.parseConstStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -158,12 +209,61 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	if p.curTokenIs(token.COMMA) {
+		stmt.Expression = p.parseMultipleAssignment(stmt.Token, stmt.Expression)
+	}
+
 	return stmt
 }
 
 func (p *Parser) advance() {
+	p.prevToken = p.curToken
 	p.curToken = p.peekToken
-	p.peekToken = p.l.Next()
+
+	if len(p.buffered) > 0 {
+		p.peekToken = p.buffered[0]
+		p.buffered = p.buffered[1:]
+	} else {
+		p.peekToken = p.l.Next()
+	}
+}
+
+// lookahead returns the n-th token after curToken, 1 being peekToken.
+func (p *Parser) lookahead(n int) *token.Token {
+	if n == 1 {
+		return p.peekToken
+	}
+
+	for len(p.buffered) < n-1 {
+		p.buffered = append(p.buffered, p.l.Next())
+	}
+
+	return p.buffered[n-2]
+}
+
+// startsAssignment reports whether curToken is a `[` starting a new line whose matching `]`
+// is followed by `=`, like `[x, y] = pair`. Newlines don't end expressions, so without
+// this check the line would index the expression before it.
+func (p *Parser) startsAssignment() bool {
+	if !p.curTokenIs(token.LSQUARE) || p.prevToken == nil || p.curToken.Line == p.prevToken.Line {
+		return false
+	}
+
+	depth := 1
+
+	for n := 1; ; n++ {
+		switch p.lookahead(n).Type {
+		case token.EOF:
+			return false
+		case token.LSQUARE:
+			depth++
+		case token.RSQUARE:
+			depth--
+			if depth == 0 {
+				return p.lookahead(n+1).Type == token.ASSIGN
+			}
+		}
+	}
 }
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 
@@ -179,7 +279,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	leftExp := prefix()
 	// So we compare the precedence of the current token.
 
-	for !p.curTokenIs(token.EOF) && precedence < p.curPrecedence() {
+	for !p.curTokenIs(token.EOF) && precedence < p.curPrecedence() && !p.startsAssignment() {
 		infix := p.infixParseFns[p.curToken.Type]
 
 		if infix == nil {
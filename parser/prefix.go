@@ -29,6 +29,42 @@ func (p *Parser) parseArray() ast.Expression {
 	return array
 }
 
+func (p *Parser) parseMap() ast.Expression {
+	m := &ast.Map{Token: p.curToken, Keys: []ast.Expression{}, Values: []ast.Expression{}}
+
+	if !p.expect(token.LBRACE) {
+		return nil
+	}
+
+	for !p.curTokenIs(token.RBRACE) {
+		// Keys are parsed above assignment, so the '=' is left for the map literal.
+		key := p.parseExpression(ASSIGNMENT)
+
+		if !p.expect(token.ASSIGN) {
+			return nil
+		}
+
+		value := p.parseExpression(LOWEST)
+
+		m.Keys = append(m.Keys, key)
+		m.Values = append(m.Values, value)
+
+		if p.curTokenIs(token.RBRACE) {
+			break
+		}
+
+		if !p.expect(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expect(token.RBRACE) {
+		return nil
+	}
+
+	return m
+}
+
 func (p *Parser) parseInteger() ast.Expression {
 	value := p.curToken
 
@@ -51,6 +87,13 @@ func (p *Parser) parseString() ast.Expression {
 
 	return s
 }
+func (p *Parser) parseNull() ast.Expression {
+	n := &ast.Null{Token: p.curToken}
+
+	p.advance()
+
+	return n
+}
 func (p *Parser) parseBoolean() ast.Expression {
 	b := &ast.Boolean{Token: p.curToken}
 
@@ -117,13 +160,48 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return ifExp
 
 }
+func (p *Parser) parseMatchExpression() ast.Expression {
+	match := &ast.MatchExpression{Token: p.curToken}
+	p.advance()
+
+	match.Value = p.parseExpression(LOWEST)
+
+	for p.curTokenIs(token.COMMENT) {
+		p.advance()
+	}
+
+	for p.curTokenIs(token.CASE) {
+		c := &ast.MatchCase{Token: p.curToken}
+		p.advance()
+
+		c.Pattern = p.parseExpression(LOWEST)
+
+		if p.curTokenIs(token.IF) {
+			p.advance()
+			c.Guard = p.parseExpression(LOWEST)
+		}
+
+		if !p.expect(token.THEN) {
+			return nil
+		}
+
+		c.Body = p.parseBlockStatement()
+		match.Cases = append(match.Cases, c)
+	}
+
+	if !p.expect(token.END) {
+		return nil
+	}
+
+	return match
+}
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 	b := &ast.BlockStatement{Token: p.curToken}
 
 	b.Statements = []ast.Statement{}
 
-	for !p.curTokenIs(token.END) && !p.curTokenIs(token.EOF) && !p.curTokenIs(token.ELSE) && !p.curTokenIs(token.ELIF) {
+	for !p.curTokenIs(token.END) && !p.curTokenIs(token.EOF) && !p.curTokenIs(token.ELSE) && !p.curTokenIs(token.ELIF) && !p.curTokenIs(token.CASE) {
 		statement := p.parseStatement()
 
 		if statement != nil {
@@ -143,7 +221,7 @@ func (p *Parser) parseLambda() ast.Expression {
 		return nil
 	}
 
-	lambda.Arguments = p.parseFunctionParameters()
+	lambda.Arguments, lambda.Defaults, lambda.Rest, lambda.Patterns = p.parseFunctionParameters()
 
 	lambda.Body = p.parseBlockStatement()
 
@@ -153,19 +231,122 @@ func (p *Parser) parseLambda() ast.Expression {
 
 	return lambda
 }
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	expressions := p.parseExpressionList(token.RPAREN)
 
+// parseFunctionParameters parses `a, [b, c], d = default, ...rest)`.
+// Defaults and patterns are nil unless a parameter has one, a pattern is named by its source.
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, map[string]ast.Expression, *ast.Identifier, map[string]ast.Expression) {
 	identifiers := []*ast.Identifier{}
+	var defaults map[string]ast.Expression
+	var patterns map[string]ast.Expression
 
-	for _, exp := range expressions {
-		ident, ok := exp.(*ast.Identifier)
-		if !ok {
-			p.addError("Expected identifier, got %v", exp.Name())
-			return nil
+	if p.curTokenIs(token.RPAREN) {
+		p.advance()
+		return identifiers, defaults, nil, patterns
+	}
+
+	for true {
+		if p.curTokenIs(token.ELLIPSIS) {
+			p.advance()
+
+			rest := &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}
+
+			if !p.expect(token.IDENT) || !p.expect(token.RPAREN) {
+				return nil, nil, nil, nil
+			}
+
+			return identifiers, defaults, rest, patterns
+		}
+
+		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}
+
+		switch p.curToken.Type {
+		case token.IDENT:
+			p.advance()
+		case token.LSQUARE, token.LBRACE:
+			pattern := p.parsePattern()
+			if pattern == nil {
+				return nil, nil, nil, nil
+			}
+
+			if patterns == nil {
+				patterns = map[string]ast.Expression{}
+			}
+
+			ident.Value = pattern.String()
+			patterns[ident.Value] = pattern
+		default:
+			p.addError("Expected identifier, got %s", p.curToken.Type)
+			return nil, nil, nil, nil
+		}
+
+		if p.curTokenIs(token.ASSIGN) {
+			p.advance()
+
+			if defaults == nil {
+				defaults = map[string]ast.Expression{}
+			}
+
+			defaults[ident.Value] = p.parseExpression(LOWEST)
+		} else if defaults != nil {
+			p.addError("Parameter %s without a default follows a parameter with a default", ident.Value)
+			return nil, nil, nil, nil
 		}
+
 		identifiers = append(identifiers, ident)
+
+		if p.curTokenIs(token.RPAREN) {
+			p.advance()
+			break
+		}
+		if !p.expect(token.COMMA) {
+			return nil, nil, nil, nil
+		}
+	}
+
+	return identifiers, defaults, nil, patterns
+}
+
+func validPattern(pattern ast.Expression) bool {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.Array:
+		for _, element := range pattern.Elements {
+			if !validPattern(element) {
+				return false
+			}
+		}
+		return true
+	case *ast.Map:
+		// Map keys are values to look up, only the values bind names.
+		for _, value := range pattern.Values {
+			if !validPattern(value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// parsePattern parses a list or map parameter pattern, holding only names and nested patterns.
+func (p *Parser) parsePattern() ast.Expression {
+	var pattern ast.Expression
+	if p.curTokenIs(token.LSQUARE) {
+		pattern = p.parseArray()
+	} else {
+		pattern = p.parseMap()
+	}
+
+	if pattern == nil {
+		return nil
+	}
+
+	if !validPattern(pattern) {
+		p.addError("Invalid parameter pattern %s", pattern)
+		return nil
 	}
 
-	return identifiers
+	return pattern
 }
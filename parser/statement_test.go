@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/lexer"
 	"github.com/pspiagicw/fener/token"
 )
 
@@ -21,6 +22,14 @@ func TestEmptyClassStatement(t *testing.T) {
 	}
 	checkTree(t, input, expectedTree)
 }
+func TestIncompleteClassStatement(t *testing.T) {
+	p := New(lexer.New("class"))
+	p.Parse()
+
+	if len(p.Errors()) == 0 {
+		t.Errorf("Expected parse errors for a class without a target")
+	}
+}
 func TestMethodClassStatement(t *testing.T) {
 
 	input := `
@@ -76,6 +85,80 @@ func TestMethodClassStatement(t *testing.T) {
 	checkTree(t, input, expectedTree)
 }
 
+func TestClassMembers(t *testing.T) {
+	input := `class Point
+const ZERO = 0
+x = 1
+static fn origin()
+end
+end`
+
+	expectedTree := []ast.Statement{
+		&ast.ClassStatement{
+			Token:  &token.Token{Type: token.CLASS, Value: "class"},
+			Target: &ast.Identifier{Value: "Point", Token: &token.Token{Type: token.IDENT, Value: "Point"}},
+			Constants: []*ast.ConstStatement{
+				{
+					Token:  &token.Token{Type: token.CONST, Value: "const", Line: 1},
+					Target: &ast.Identifier{Value: "ZERO", Token: &token.Token{Type: token.IDENT, Value: "ZERO", Line: 1}},
+					Value:  &ast.Integer{Value: 0, Token: &token.Token{Type: token.INT, Value: "0", Line: 1}},
+				},
+			},
+			Fields: []*ast.AssignmentExpression{
+				{
+					Token:  &token.Token{Type: token.ASSIGN, Value: "=", Line: 2},
+					Target: &ast.Identifier{Value: "x", Token: &token.Token{Type: token.IDENT, Value: "x", Line: 2}},
+					Value:  &ast.Integer{Value: 1, Token: &token.Token{Type: token.INT, Value: "1", Line: 2}},
+				},
+			},
+			Methods: []*ast.FunctionStatement{},
+			Statics: []*ast.FunctionStatement{
+				{
+					Token:     &token.Token{Type: token.FUNCTION, Value: "fn", Line: 3},
+					Target:    &ast.Identifier{Value: "origin", Token: &token.Token{Type: token.IDENT, Value: "origin", Line: 3}},
+					Arguments: []*ast.Identifier{},
+					Body:      &ast.BlockStatement{Token: &token.Token{Type: token.END, Value: "end", Line: 4}, Statements: []ast.Statement{}},
+				},
+			},
+		},
+	}
+	checkTree(t, input, expectedTree)
+}
+
+func TestTraitStatement(t *testing.T) {
+	input := `trait Named
+fn name()
+end
+end
+class Dog
+include Named
+end`
+
+	expectedTree := []ast.Statement{
+		&ast.TraitStatement{
+			Token:  &token.Token{Type: token.TRAIT, Value: "trait"},
+			Target: &ast.Identifier{Value: "Named", Token: &token.Token{Type: token.IDENT, Value: "Named"}},
+			Methods: []*ast.FunctionStatement{
+				{
+					Token:     &token.Token{Type: token.FUNCTION, Value: "fn", Line: 1},
+					Target:    &ast.Identifier{Value: "name", Token: &token.Token{Type: token.IDENT, Value: "name", Line: 1}},
+					Arguments: []*ast.Identifier{},
+					Body:      &ast.BlockStatement{Token: &token.Token{Type: token.END, Value: "end", Line: 2}, Statements: []ast.Statement{}},
+				},
+			},
+		},
+		&ast.ClassStatement{
+			Token:  &token.Token{Type: token.CLASS, Value: "class", Line: 4},
+			Target: &ast.Identifier{Value: "Dog", Token: &token.Token{Type: token.IDENT, Value: "Dog", Line: 4}},
+			Includes: []*ast.Identifier{
+				{Value: "Named", Token: &token.Token{Type: token.IDENT, Value: "Named", Line: 5}},
+			},
+			Methods: []*ast.FunctionStatement{},
+		},
+	}
+	checkTree(t, input, expectedTree)
+}
+
 func TestParserTest(t *testing.T) {
 	input := `
     test "Test 1"
@@ -181,3 +264,24 @@ func TestParserReturnString(t *testing.T) {
 
 	checkTree(t, input, expectedTree)
 }
+
+func TestImportStatement(t *testing.T) {
+	input := `
+    import "math"
+    import "lib/shapes" as s
+    `
+
+	expectedTree := []ast.Statement{
+		&ast.ImportStatement{
+			Token: &token.Token{Type: token.IMPORT, Value: "import", Line: 1},
+			Path:  &ast.String{Value: "math", Token: &token.Token{Type: token.STRING, Value: "math", Line: 1}},
+		},
+		&ast.ImportStatement{
+			Token: &token.Token{Type: token.IMPORT, Value: "import", Line: 2},
+			Path:  &ast.String{Value: "lib/shapes", Token: &token.Token{Type: token.STRING, Value: "lib/shapes", Line: 2}},
+			Alias: &ast.Identifier{Value: "s", Token: &token.Token{Type: token.IDENT, Value: "s", Line: 2}},
+		},
+	}
+
+	checkTree(t, input, expectedTree)
+}
@@ -12,6 +12,11 @@ func (p *Parser) parseClassStatement() ast.Statement {
 
 	value := p.parseExpression(LOWEST)
 
+	if value == nil {
+		p.addError("Expected target for class statement")
+		return nil
+	}
+
 	ident, ok := value.(*ast.Identifier)
 
 	if !ok {
@@ -23,7 +28,73 @@ func (p *Parser) parseClassStatement() ast.Statement {
 	stmt.Methods = []*ast.FunctionStatement{}
 
 	for !p.curTokenIs(token.END) && !p.curTokenIs(token.EOF) {
-		stmt.Methods = append(stmt.Methods, p.parseFunctionStatement())
+		switch p.curToken.Type {
+		case token.COMMENT:
+			p.advance()
+		case token.INCLUDE:
+			p.advance()
+			stmt.Includes = append(stmt.Includes, &ast.Identifier{Token: p.curToken, Value: p.curToken.Value})
+			if !p.expect(token.IDENT) {
+				return nil
+			}
+		case token.CONST:
+			if constant, ok := p.parseConstStatement().(*ast.ConstStatement); ok {
+				stmt.Constants = append(stmt.Constants, constant)
+			}
+		case token.STATIC:
+			p.advance()
+			if !p.curTokenIs(token.FUNCTION) {
+				p.addError("Expected fn after static, got %s", p.curToken.Type)
+				return nil
+			}
+			stmt.Statics = append(stmt.Statics, p.parseFunctionStatement())
+		case token.IDENT:
+			start := p.curToken
+			field, ok := p.parseExpression(LOWEST).(*ast.AssignmentExpression)
+			if !ok {
+				p.addError("Expected field default for %s in class body", start.Value)
+				return nil
+			}
+			if _, ok := field.Target.(*ast.Identifier); !ok {
+				p.addError("Expected identifier target for field default, got %s", field.Target.String())
+				return nil
+			}
+			stmt.Fields = append(stmt.Fields, field)
+		default:
+			stmt.Methods = append(stmt.Methods, p.parseFunctionStatement())
+		}
+	}
+
+	if !p.expect(token.END) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseTraitStatement() ast.Statement {
+	stmt := &ast.TraitStatement{Token: p.curToken}
+
+	p.advance()
+
+	stmt.Target = &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}
+
+	if !p.expect(token.IDENT) {
+		return nil
+	}
+
+	stmt.Methods = []*ast.FunctionStatement{}
+
+	for !p.curTokenIs(token.END) && !p.curTokenIs(token.EOF) {
+		switch p.curToken.Type {
+		case token.COMMENT:
+			p.advance()
+		case token.FUNCTION:
+			stmt.Methods = append(stmt.Methods, p.parseFunctionStatement())
+		default:
+			p.addError("Expected method in trait %s, got %s", stmt.Target.Value, p.curToken.Type)
+			return nil
+		}
 	}
 
 	if !p.expect(token.END) {
@@ -33,6 +104,33 @@ func (p *Parser) parseClassStatement() ast.Statement {
 	return stmt
 }
 
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	p.advance()
+
+	if !p.curTokenIs(token.STRING) {
+		p.addError("Expected string path for import statement, got %s", p.curToken.Type)
+		return nil
+	}
+
+	stmt.Path = &ast.String{Token: p.curToken, Value: p.curToken.Value}
+
+	p.advance()
+
+	if p.curTokenIs(token.AS) {
+		p.advance()
+
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}
+
+		if !p.expect(token.IDENT) {
+			return nil
+		}
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseTestStatement() *ast.TestStatement {
 	stmt := &ast.TestStatement{Token: p.curToken}
 
@@ -70,8 +168,61 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	// Several values are returned as a list.
+	if p.curTokenIs(token.COMMA) {
+		values := &ast.Array{Token: stmt.Token, Elements: []ast.Expression{stmt.Value}}
+		for p.curTokenIs(token.COMMA) {
+			p.advance()
+			values.Elements = append(values.Elements, p.parseExpression(LOWEST))
+		}
+		stmt.Value = values
+	}
+
+	return stmt
+}
+func (p *Parser) parseLetStatement() ast.Statement {
+	stmt := &ast.LetStatement{Token: p.curToken}
+
+	target, value, ok := p.parseBinding()
+	if !ok {
+		return nil
+	}
+
+	stmt.Target = target
+	stmt.Value = value
+
+	return stmt
+}
+func (p *Parser) parseConstStatement() ast.Statement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
+	target, value, ok := p.parseBinding()
+	if !ok {
+		return nil
+	}
+
+	stmt.Target = target
+	stmt.Value = value
+
 	return stmt
 }
+
+// parseBinding parses the `name = value` following `let` or `const`.
+func (p *Parser) parseBinding() (*ast.Identifier, ast.Expression, bool) {
+	p.advance()
+
+	target := &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}
+
+	if !p.expect(token.IDENT) {
+		return nil, nil, false
+	}
+
+	if !p.expect(token.ASSIGN) {
+		return nil, nil, false
+	}
+
+	return target, p.parseExpression(LOWEST), true
+}
 func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 	stmt := &ast.WhileStatement{Token: p.curToken}
 
@@ -106,7 +257,7 @@ func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
 		return nil
 	}
 
-	stmt.Arguments = p.parseFunctionParameters()
+	stmt.Arguments, stmt.Defaults, stmt.Rest, stmt.Patterns = p.parseFunctionParameters()
 
 	stmt.Body = p.parseBlockStatement()
 
@@ -0,0 +1,186 @@
+// Package fener embeds the fener interpreter in Go programs.
+package fener
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pspiagicw/fener/ast"
+	"github.com/pspiagicw/fener/budget"
+	"github.com/pspiagicw/fener/eval"
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/parser"
+)
+
+type Config struct {
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Paths are searched for modules, after the directory of the importing file.
+	Paths []string
+
+	// Limits are applied to every run, call or evaluation separately.
+	Limits budget.Limits
+
+	// Allow grants capabilities to the builtins, by default none are granted.
+	Allow []object.Capability
+}
+
+// Interpreter keeps the global environment of a fener program across runs.
+type Interpreter struct {
+	env    *object.Environment
+	loader *eval.Loader
+	limits budget.Limits
+	ctx    context.Context
+}
+
+// ParseError holds all the errors reported by the parser.
+type ParseError struct {
+	Errors []string
+}
+
+func (p *ParseError) Error() string {
+	return fmt.Sprintf("parsing failed: %s", strings.Join(p.Errors, ", "))
+}
+
+// halt carries an evaluation error out of the evaluator.
+type halt struct {
+	err error
+}
+
+func New(config *Config) *Interpreter {
+	if config == nil {
+		config = &Config{}
+	}
+
+	env := object.NewSandboxedEnvironment(object.NewCapabilities(config.Allow...))
+
+	if config.Stdout != nil {
+		env.Stdout = config.Stdout
+	}
+
+	if config.Stderr != nil {
+		env.Stderr = config.Stderr
+	}
+
+	return &Interpreter{
+		env:    env,
+		loader: eval.NewLoader(config.Paths),
+		limits: config.Limits,
+		ctx:    context.Background(),
+	}
+}
+
+// WithContext returns an interpreter sharing the same globals whose runs stop when ctx is done.
+func (i *Interpreter) WithContext(ctx context.Context) *Interpreter {
+	scoped := *i
+	scoped.ctx = ctx
+	return &scoped
+}
+
+// RunString evaluates input and returns the value of the last statement.
+func (i *Interpreter) RunString(input string) (object.Object, error) {
+	return i.run(input, "")
+}
+
+// RunFile evaluates a file, imports inside it are resolved relative to the file.
+func (i *Interpreter) RunFile(filename string) (object.Object, error) {
+	contents, err := os.ReadFile(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return i.run(string(contents), filename)
+}
+
+// Set binds a global variable.
+func (i *Interpreter) Set(name string, value object.Object) {
+	i.env.Set(name, value)
+}
+
+// Get returns a global variable.
+func (i *Interpreter) Get(name string) (object.Object, bool) {
+	value := i.env.Get(name)
+
+	return value, value != nil
+}
+
+// Register binds a Go function as a global builtin, see object.NewBuiltin for the conversion rules.
+func (i *Interpreter) Register(name string, fn interface{}) error {
+	return object.RegisterFunc(i.env, name, fn)
+}
+
+// Call calls the global function, builtin or class called name.
+func (i *Interpreter) Call(name string, args ...object.Object) (object.Object, error) {
+	fn, ok := i.Get(name)
+
+	if !ok {
+		return nil, fmt.Errorf("function %s not found", name)
+	}
+
+	return i.evaluate("", func(e *eval.Evaluator) object.Object {
+		return e.Call(fn, args)
+	})
+}
+
+func (i *Interpreter) run(input string, filename string) (object.Object, error) {
+	program, err := parse(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return i.evaluate(filename, func(e *eval.Evaluator) object.Object {
+		return e.Eval(program, i.env)
+	})
+}
+
+func (i *Interpreter) evaluate(filename string, fn func(*eval.Evaluator) object.Object) (result object.Object, err error) {
+	e := eval.New(func(err error) {
+		panic(halt{err: err})
+	})
+	e.File = filename
+	e.Loader = i.loader
+	e.Budget = budget.New(i.ctx, i.limits)
+
+	defer func() {
+		if r := recover(); r != nil {
+			h, ok := r.(halt)
+
+			if !ok {
+				panic(r)
+			}
+
+			result, err = nil, h.err
+		}
+	}()
+
+	result = fn(e)
+
+	if ret, ok := result.(*object.Return); ok {
+		result = ret.Value
+	}
+
+	if result == nil {
+		result = &object.Null{}
+	}
+
+	return result, nil
+}
+
+func parse(input string) (*ast.Program, error) {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.Parse()
+
+	if len(p.Errors()) > 0 {
+		return nil, &ParseError{Errors: p.Errors()}
+	}
+
+	return program, nil
+}
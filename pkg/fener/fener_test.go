@@ -0,0 +1,312 @@
+package fener
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pspiagicw/fener/budget"
+	"github.com/pspiagicw/fener/object"
+)
+
+func TestRunString(t *testing.T) {
+	var stdout bytes.Buffer
+
+	i := New(&Config{Stdout: &stdout})
+
+	value, err := i.RunString(`print("Hello ", "World") 1 + 2`)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkInteger(t, value, 3)
+
+	if stdout.String() != "Hello World\n" {
+		t.Fatalf("Expected output %q, got %q", "Hello World\n", stdout.String())
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "shapes.fn"), `fn area(w, h) w * h end`)
+	writeFile(t, filepath.Join(dir, "main.fn"), `import "shapes" shapes.area(3, 4)`)
+
+	i := New(&Config{Allow: []object.Capability{object.FS_READ_CAP}})
+
+	value, err := i.RunFile(filepath.Join(dir, "main.fn"))
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkInteger(t, value, 12)
+}
+
+func TestGlobals(t *testing.T) {
+	i := New(nil)
+
+	i.Set("limit", &object.Integer{Value: 10})
+
+	_, err := i.RunString(`doubled = limit * 2`)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	value, ok := i.Get("doubled")
+
+	if !ok {
+		t.Fatalf("Expected doubled to be set")
+	}
+
+	checkInteger(t, value, 20)
+
+	if _, ok := i.Get("missing"); ok {
+		t.Fatalf("Expected missing to not be set")
+	}
+}
+
+func TestCall(t *testing.T) {
+	i := New(nil)
+
+	_, err := i.RunString(`fn add(a, b) return a + b end`)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	value, err := i.Call("add", &object.Integer{Value: 2}, &object.Integer{Value: 3})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkInteger(t, value, 5)
+
+	if _, err := i.Call("subtract"); err == nil {
+		t.Fatalf("Expected error calling undefined function")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	i := New(nil)
+
+	err := i.Register("repeat", func(s string, n int) []string {
+		result := []string{}
+		for j := 0; j < n; j++ {
+			result = append(result, s)
+		}
+		return result
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	value, err := i.RunString(`len(repeat("ab", 3))`)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkInteger(t, value, 3)
+
+	_, err = i.RunString(`repeat(3, "ab")`)
+
+	if err == nil || !strings.Contains(err.Error(), "argument 1 to repeat: expected string, got INTEGER") {
+		t.Fatalf("Expected type mismatch error, got %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	i := New(&Config{Limits: budget.Limits{MaxSteps: 10000}})
+
+	_, err := i.RunString(`while true then end`)
+
+	var limitErr *budget.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != budget.STEPS {
+		t.Fatalf("Expected step limit error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = i.WithContext(ctx).RunString(`while true then end`)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancellation error, got %v", err)
+	}
+
+	// Every run gets a fresh budget.
+	if _, err := i.RunString(`1 + 1`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestCallLimits(t *testing.T) {
+	i := New(&Config{Limits: budget.Limits{MaxSteps: 200}})
+
+	if _, err := i.RunString(`fn double(arr) map(arr, fn(x) x * 2 end) end`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := i.RunString(`while true then end`); err == nil {
+		t.Fatalf("Expected step limit error")
+	}
+
+	arr := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}}}
+
+	// Builtins calling back into fener must use the budget of the current call.
+	for j := 0; j < 2; j++ {
+		value, err := i.Call("double", arr)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		doubled, ok := value.(*object.Array)
+
+		if !ok || len(doubled.Elements) != 2 {
+			t.Fatalf("Expected an array of 2 elements, got %v", value)
+		}
+
+		checkInteger(t, doubled.Elements[0], 2)
+		checkInteger(t, doubled.Elements[1], 4)
+	}
+
+	long := &object.Array{}
+	for j := 0; j < 100; j++ {
+		long.Elements = append(long.Elements, &object.Integer{Value: int64(j)})
+	}
+
+	_, err := i.Call("double", long)
+
+	var limitErr *budget.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != budget.STEPS {
+		t.Fatalf("Expected step limit error, got %v", err)
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	_, err := New(nil).RunString(`getenv("HOME")`)
+
+	var permErr *object.PermissionError
+	if !errors.As(err, &permErr) {
+		t.Fatalf("Expected permission error, got %v", err)
+	}
+
+	i := New(&Config{Allow: []object.Capability{object.ENV_CAP}})
+
+	if _, err := i.RunString(`getenv("HOME")`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestFetchCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	i := New(&Config{Allow: []object.Capability{object.NET_CAP}}).WithContext(ctx)
+
+	_, err := i.RunString(fmt.Sprintf(`fetch(%q)`, server.URL))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the request to stop with the run, got %v", err)
+	}
+}
+
+func TestModuleRuns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "lib.fn"), "fn twice(xs) map(xs, fn(x) x * 2 end) end\nfn get(url) fetch(url) end\n")
+	writeFile(t, filepath.Join(dir, "main.fn"), `import "lib"`)
+
+	i := New(&Config{Allow: []object.Capability{object.FS_READ_CAP, object.NET_CAP}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if _, err := i.WithContext(ctx).RunFile(filepath.Join(dir, "main.fn")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cancel()
+
+	// The module's functions follow the run calling them, not the one which imported it.
+	value, err := i.RunString(`len(lib.twice(1..1000))`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkInteger(t, value, 1000)
+
+	value, err = i.RunString(fmt.Sprintf(`lib.get(%q)`, server.URL))
+	if err != nil || value.Pretty() != "ok" {
+		t.Fatalf("Expected ok, got %v (%v)", value, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	i := New(nil)
+
+	_, err := i.RunString(`a = (1 + `)
+
+	var parseError *ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("Expected parse error, got %v", err)
+	}
+
+	_, err = i.RunString(`missing + 1`)
+
+	if err == nil || err.Error() != "Identifier not found: missing" {
+		t.Fatalf("Expected evaluation error, got %v", err)
+	}
+
+	// The interpreter stays usable after an error.
+	value, err := i.RunString(`5`)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkInteger(t, value, 5)
+}
+
+func writeFile(t *testing.T, path string, contents string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
+func checkInteger(t *testing.T, obj object.Object, expected int64) {
+	t.Helper()
+
+	result, ok := obj.(*object.Integer)
+
+	if !ok {
+		t.Fatalf("Object is not an Integer. Got: %T", obj)
+	}
+
+	if result.Value != expected {
+		t.Fatalf("Expected %d, got %d", expected, result.Value)
+	}
+}
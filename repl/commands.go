@@ -0,0 +1,230 @@
+package repl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/object"
+	"github.com/pspiagicw/fener/parser"
+	"github.com/pspiagicw/goreland"
+)
+
+type command struct {
+	name        string
+	args        string
+	description string
+	run         func(s *session, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"help", "", "Show the available commands", (*session).helpCommand},
+		{"env", "", "List the bindings and their types", (*session).envCommand},
+		{"ast", "", "Toggle printing the AST of every input", (*session).astCommand},
+		{"tokens", "", "Toggle printing the tokens of every input", (*session).tokensCommand},
+		{"load", "<file>", "Evaluate a file into the session", (*session).loadCommand},
+		{"save", "<file>", "Write the successful inputs to a replayable file", (*session).saveCommand},
+		{"snapshot", "<file>", "Write the plain data bindings to a JSON snapshot", (*session).snapshotCommand},
+		{"restore", "<file>", "Replay a saved session or load a .json snapshot", (*session).restoreCommand},
+		{"reset", "", "Clear all bindings", (*session).resetCommand},
+		{"time", "", "Toggle timing every evaluation", (*session).timeCommand},
+		{"quit", "", "Exit the repl", (*session).quitCommand},
+	}
+}
+
+func (s *session) command(line string) error {
+	fields := strings.Fields(strings.TrimPrefix(line, ":"))
+
+	if len(fields) == 0 {
+		goreland.LogError("Missing command, try :help")
+		return nil
+	}
+
+	for _, c := range commands {
+		if c.name == fields[0] {
+			return c.run(s, fields[1:])
+		}
+	}
+
+	goreland.LogError("Unknown command :%s, try :help", fields[0])
+	return nil
+}
+
+func (s *session) helpCommand(args []string) error {
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+
+	for _, c := range commands {
+		fmt.Fprintf(w, ":%s %s\t%s\n", c.name, c.args, c.description)
+	}
+
+	return w.Flush()
+}
+
+func (s *session) envCommand(args []string) error {
+	names := []string{}
+
+	for name, value := range s.env.Bindings {
+		if _, ok := value.(*object.Builtin); ok {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+
+	for _, name := range names {
+		value := s.env.Bindings[name]
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, value.Type(), value.String())
+	}
+
+	return w.Flush()
+}
+
+func (s *session) astCommand(args []string) error {
+	s.opts.PrintAST = !s.opts.PrintAST
+	fmt.Fprintf(s.out, "printing AST: %t\n", s.opts.PrintAST)
+	return nil
+}
+
+func (s *session) tokensCommand(args []string) error {
+	s.printTokens = !s.printTokens
+	fmt.Fprintf(s.out, "printing tokens: %t\n", s.printTokens)
+	return nil
+}
+
+func (s *session) timeCommand(args []string) error {
+	s.timing = !s.timing
+	fmt.Fprintf(s.out, "timing: %t\n", s.timing)
+	return nil
+}
+
+func (s *session) loadCommand(args []string) error {
+	if len(args) != 1 {
+		goreland.LogError("Usage: :load <file>")
+		return nil
+	}
+
+	contents, err := os.ReadFile(args[0])
+
+	if err != nil {
+		goreland.LogError("Error reading file: %v", err)
+		return nil
+	}
+
+	l := lexer.New(string(contents))
+	p := parser.New(l)
+	program := p.Parse()
+
+	if len(p.Errors()) > 0 {
+		for _, err := range p.Errors() {
+			goreland.LogError(err)
+		}
+		return nil
+	}
+
+	file := s.eval.File
+	s.eval.File = args[0]
+	defer func() { s.eval.File = file }()
+
+	s.run(program, string(contents))
+
+	fmt.Fprintf(s.out, "loaded %s\n", args[0])
+	return nil
+}
+
+func (s *session) saveCommand(args []string) error {
+	if len(args) != 1 {
+		goreland.LogError("Usage: :save <file>")
+		return nil
+	}
+
+	transcript := ";; fener session\n\n" + strings.Join(s.inputs, "\n\n") + "\n"
+
+	if err := os.WriteFile(args[0], []byte(transcript), 0644); err != nil {
+		goreland.LogError("Error saving session: %v", err)
+		return nil
+	}
+
+	fmt.Fprintf(s.out, "saved %d inputs to %s\n", len(s.inputs), args[0])
+	return nil
+}
+
+func (s *session) snapshotCommand(args []string) error {
+	if len(args) != 1 {
+		goreland.LogError("Usage: :snapshot <file>")
+		return nil
+	}
+
+	snapshot, errors := object.TakeSnapshot(s.env)
+
+	for _, err := range errors {
+		goreland.LogError("Skipped %v", err)
+	}
+
+	contents, err := json.MarshalIndent(snapshot, "", "  ")
+
+	if err != nil {
+		goreland.LogError("Error encoding snapshot: %v", err)
+		return nil
+	}
+
+	if err := os.WriteFile(args[0], contents, 0644); err != nil {
+		goreland.LogError("Error writing snapshot: %v", err)
+		return nil
+	}
+
+	fmt.Fprintf(s.out, "saved %d bindings to %s\n", len(snapshot.Bindings), args[0])
+	return nil
+}
+
+func (s *session) restoreCommand(args []string) error {
+	if len(args) != 1 {
+		goreland.LogError("Usage: :restore <file>")
+		return nil
+	}
+
+	if filepath.Ext(args[0]) != ".json" {
+		return s.loadCommand(args)
+	}
+
+	contents, err := os.ReadFile(args[0])
+
+	if err != nil {
+		goreland.LogError("Error reading snapshot: %v", err)
+		return nil
+	}
+
+	snapshot := &object.Snapshot{}
+
+	if err := json.Unmarshal(contents, snapshot); err != nil {
+		goreland.LogError("Error decoding snapshot: %v", err)
+		return nil
+	}
+
+	for _, err := range snapshot.Restore(s.env) {
+		goreland.LogError("Skipped %v", err)
+	}
+
+	fmt.Fprintf(s.out, "restored %s\n", args[0])
+	return nil
+}
+
+func (s *session) resetCommand(args []string) error {
+	s.reset()
+	fmt.Fprintln(s.out, "environment cleared")
+	return nil
+}
+
+func (s *session) quitCommand(args []string) error {
+	return errQuit
+}
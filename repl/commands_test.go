@@ -0,0 +1,157 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pspiagicw/fener/argparse"
+)
+
+func TestCommands(t *testing.T) {
+	var out bytes.Buffer
+
+	s := newSession(&argparse.Opts{}, &out)
+
+	s.handle("a = 1")
+	s.handle(`name = "fener"`)
+
+	out.Reset()
+	s.handle(":env")
+
+	expected := "a     INTEGER  int(1)\nname  STRING   str(fener)\n"
+	if out.String() != expected {
+		t.Errorf("Expected env %q, got %q", expected, out.String())
+	}
+
+	s.handle(":ast")
+	if !s.opts.PrintAST {
+		t.Errorf("Expected :ast to enable printing the AST")
+	}
+
+	s.handle(":reset")
+	out.Reset()
+	s.handle(":env")
+
+	if out.String() != "" {
+		t.Errorf("Expected empty env after reset, got %q", out.String())
+	}
+
+	if err := s.handle(":quit"); err != errQuit {
+		t.Errorf("Expected :quit to end the session, got %v", err)
+	}
+}
+
+func TestLoadCommand(t *testing.T) {
+	var out bytes.Buffer
+
+	file := filepath.Join(t.TempDir(), "lib.fn")
+	os.WriteFile(file, []byte("fn double(x)\n return x * 2\nend\n"), 0644)
+
+	s := newSession(&argparse.Opts{}, &out)
+	s.handle(":load " + file)
+
+	out.Reset()
+	s.handle("double(21)")
+
+	if strings.TrimSpace(out.String()) != "int(42)" {
+		t.Errorf("Expected int(42), got %q", out.String())
+	}
+}
+
+func TestShowInstance(t *testing.T) {
+	var out bytes.Buffer
+
+	s := newSession(&argparse.Opts{}, &out)
+	s.handle("class P fn init() this.x = 1 end end")
+	s.handle("class Q fn to_string() \"q!\" end end")
+
+	out.Reset()
+	s.handle("P()")
+	s.handle("Q()")
+
+	if out.String() != "P{x->int(1)}\nq!\n" {
+		t.Errorf("Expected instances to be shown with their fields or to_string, got %q", out.String())
+	}
+}
+
+func TestShowFailure(t *testing.T) {
+	var out bytes.Buffer
+
+	s := newSession(&argparse.Opts{}, &out)
+
+	for _, input := range []string{"[nope, 1]", "{nope = 1}", "len(undefined)"} {
+		s.handle(input)
+
+		if !s.failed {
+			t.Errorf("Expected %q to fail", input)
+		}
+	}
+
+	if out.String() != "" {
+		t.Errorf("Expected nothing to be shown, got %q", out.String())
+	}
+}
+
+func TestBlockDepth(t *testing.T) {
+	tt := []struct {
+		input string
+		depth int
+	}{
+		{"1 + 2", 0},
+		{"fn add(a, b)", 1},
+		{"if a then 1 else 2 end", 0},
+		{"while true then if a then", 2},
+		{"match x", 1},
+		{"match x case 1 then 2 end", 0},
+		{"trait Describe", 1},
+	}
+
+	for _, tc := range tt {
+		if got := blockDepth(tc.input); got != tc.depth {
+			t.Errorf("blockDepth(%q) = %d, want %d", tc.input, got, tc.depth)
+		}
+	}
+}
+
+func TestSaveRestore(t *testing.T) {
+	var out bytes.Buffer
+
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "session.fn")
+	snapshot := filepath.Join(dir, "session.json")
+
+	s := newSession(&argparse.Opts{}, &out)
+	s.handle("a = 20")
+	s.handle("missing + 1")
+	s.handle("b = a + 1")
+	s.handle("const c = 1")
+	s.handle(":save " + transcript)
+	s.handle(":snapshot " + snapshot)
+
+	contents, _ := os.ReadFile(transcript)
+
+	if string(contents) != ";; fener session\n\na = 20\n\nb = a + 1\n\nconst c = 1\n" {
+		t.Errorf("Unexpected transcript %q", string(contents))
+	}
+
+	for _, file := range []string{transcript, snapshot} {
+		restored := newSession(&argparse.Opts{}, &out)
+		restored.handle(":restore " + file)
+
+		out.Reset()
+		restored.handle("a + b")
+
+		if strings.TrimSpace(out.String()) != "int(41)" {
+			t.Errorf("Expected int(41) after restoring %s, got %q", file, out.String())
+		}
+
+		restored.handle("c = 2")
+
+		if !restored.failed {
+			t.Errorf("Expected c to stay a constant after restoring %s", file)
+		}
+	}
+}
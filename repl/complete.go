@@ -0,0 +1,115 @@
+package repl
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/object"
+)
+
+// completer suggests keywords and bindings, and the fields and methods of the value before a `.`.
+type completer struct {
+	session *session
+}
+
+// Do implements readline.AutoCompleter.
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	prefix, target := splitWord(string(line[:pos]))
+
+	var candidates []string
+
+	if target != "" {
+		candidates = members(c.session.env.Get(target))
+	} else {
+		candidates = append(lexer.Keywords(), bindings(c.session.env)...)
+	}
+
+	return matches(candidates, prefix), len([]rune(prefix))
+}
+
+// splitWord returns the word being typed and, if it follows a `.`, the identifier before it.
+func splitWord(line string) (string, string) {
+	runes := []rune(line)
+
+	start := len(runes)
+	for start > 0 && isIdentifier(runes[start-1]) {
+		start--
+	}
+
+	word := string(runes[start:])
+
+	if start == 0 || runes[start-1] != '.' {
+		return word, ""
+	}
+
+	end := start - 1
+	begin := end
+	for begin > 0 && isIdentifier(runes[begin-1]) {
+		begin--
+	}
+
+	return word, string(runes[begin:end])
+}
+
+func isIdentifier(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// bindings collects the names bound anywhere in the environment chain, including builtins.
+func bindings(env *object.Environment) []string {
+	names := []string{}
+
+	for ; env != nil; env = env.Outer {
+		for name := range env.Bindings {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// members lists the fields and methods reachable with `.` on value.
+func members(value object.Object) []string {
+	names := []string{}
+
+	switch value := value.(type) {
+	case *object.Instance:
+		for name := range value.Map {
+			names = append(names, name)
+		}
+		for name := range value.Class.Methods {
+			names = append(names, name)
+		}
+	case *object.Class:
+		for name := range value.Constants {
+			names = append(names, name)
+		}
+		for name := range value.Statics {
+			names = append(names, name)
+		}
+	case *object.Module:
+		names = append(names, value.Names()...)
+	}
+
+	return names
+}
+
+// matches returns the remainder of every candidate starting with prefix, sorted and without duplicates.
+func matches(candidates []string, prefix string) [][]rune {
+	sort.Strings(candidates)
+
+	result := [][]rune{}
+	last := ""
+
+	for _, candidate := range candidates {
+		if candidate == last || !strings.HasPrefix(candidate, prefix) {
+			continue
+		}
+		last = candidate
+		result = append(result, []rune(strings.TrimPrefix(candidate, prefix)))
+	}
+
+	return result
+}
@@ -0,0 +1,53 @@
+package repl
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pspiagicw/fener/argparse"
+)
+
+func TestComplete(t *testing.T) {
+	var out bytes.Buffer
+
+	s := newSession(&argparse.Opts{}, &out)
+	s.handle("class Point\n fn init(x, y)\n this.x = x\n this.y = y\n end\n fn norm()\n return this.x\n end\n static fn origin()\n return Point(0, 0)\n end\nend")
+	s.handle("p = Point(1, 2)")
+	s.handle("counter = 1")
+
+	c := &completer{session: s}
+
+	tt := []struct {
+		line     string
+		expected []string
+		length   int
+	}{
+		{"wh", []string{"ile"}, 2},
+		{"x = cou", []string{"nter"}, 3},
+		{"pri", []string{"nt"}, 3},
+		{"p.", []string{"init", "norm", "x", "y"}, 0},
+		{"p.n", []string{"orm"}, 1},
+		{"Point.o", []string{"rigin"}, 1},
+		{"unknown.", []string{}, 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.line, func(t *testing.T) {
+			candidates, length := c.Do([]rune(tc.line), len(tc.line))
+
+			got := []string{}
+			for _, candidate := range candidates {
+				got = append(got, string(candidate))
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected completions %v, got %v", tc.expected, got)
+			}
+
+			if length != tc.length {
+				t.Errorf("Expected prefix length %d, got %d", tc.length, length)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/pspiagicw/fener/lexer"
+	"github.com/pspiagicw/fener/token"
+)
+
+const (
+	prompt         = ">>> "
+	continuePrompt = "... "
+)
+
+// reader reads complete statements, lines are joined until every block is closed with `end`.
+// It uses readline directly instead of regolith, which always keeps its history in
+// /tmp/readline.tmp, counts block words inside identifiers and ends on Ctrl-C.
+type reader struct {
+	readline *readline.Instance
+}
+
+func newReader(historyFile string, complete readline.AutoCompleter) (*reader, error) {
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          prompt,
+		HistoryFile:     historyFile,
+		InterruptPrompt: "^C",
+		EOFPrompt:       "^D",
+		AutoComplete:    complete,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader{readline: rl}, nil
+}
+
+// historyFile returns the path of the history file under the user's config directory.
+func historyFile() string {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return ""
+	}
+
+	dir = filepath.Join(dir, "fener")
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "history")
+}
+
+func (r *reader) Input() (string, error) {
+	var lines []string
+
+	depth := 0
+
+	defer r.readline.SetPrompt(prompt)
+
+	for {
+		line, err := r.readline.Readline()
+
+		if err == readline.ErrInterrupt {
+			// Drop the partial input and start over.
+			lines = nil
+			depth = 0
+			r.readline.SetPrompt(prompt)
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		line = strings.TrimSpace(line)
+
+		if len(line) == 0 {
+			continue
+		}
+
+		lines = append(lines, line)
+		depth += blockDepth(line)
+
+		if depth <= 0 {
+			break
+		}
+
+		r.readline.SetPrompt(continuePrompt)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (r *reader) Close() {
+	r.readline.Close()
+}
+
+// blockDepth counts the blocks opened by a line minus the ones it closes.
+func blockDepth(line string) int {
+	l := lexer.New(line)
+
+	depth := 0
+
+	for t := l.Next(); t.Type != token.EOF; t = l.Next() {
+		switch t.Type {
+		case token.IF, token.FUNCTION, token.WHILE, token.CLASS, token.TEST, token.MATCH, token.TRAIT:
+			depth++
+		case token.END:
+			depth--
+		}
+	}
+
+	return depth
+}
@@ -1,8 +1,13 @@
 package repl
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/pspiagicw/fener/argparse"
 	"github.com/pspiagicw/fener/ast"
@@ -11,11 +16,81 @@ import (
 	"github.com/pspiagicw/fener/lexer"
 	"github.com/pspiagicw/fener/object"
 	"github.com/pspiagicw/fener/parser"
+	"github.com/pspiagicw/fener/token"
 	"github.com/pspiagicw/goreland"
-	"github.com/pspiagicw/regolith"
 	"github.com/sanity-io/litter"
 )
 
+// errQuit is returned by the :quit command to end the session.
+var errQuit = errors.New("quit")
+
+type session struct {
+	opts *argparse.Opts
+	out  io.Writer
+
+	env  *object.Environment
+	eval *eval.Evaluator
+
+	printTokens bool
+	timing      bool
+
+	// inputs are the inputs that evaluated without errors, in order.
+	inputs []string
+	failed bool
+}
+
+func newSession(opts *argparse.Opts, out io.Writer) *session {
+	s := &session{
+		opts: opts,
+		out:  out,
+	}
+
+	s.reset()
+
+	return s
+}
+
+// reset starts over with a fresh environment.
+func (s *session) reset() {
+	s.env = object.NewEnvironment()
+	s.env.Stdout = s.out
+
+	s.eval = eval.New(func(err error) {
+		s.failed = true
+		goreland.LogError(err.Error())
+	})
+
+	s.inputs = []string{}
+}
+
+// run evaluates a program and records its source if it succeeds.
+func (s *session) run(program *ast.Program, source string) object.Object {
+	s.failed = false
+
+	result := s.eval.Eval(program, s.env)
+
+	if !s.failed {
+		s.inputs = append(s.inputs, source)
+	}
+
+	return result
+}
+
+// show echoes a result, instances are rendered with their to_string method when they have one.
+func (s *session) show(value object.Object) {
+	if value == nil {
+		return
+	}
+
+	out, err := object.Inspect(value, s.eval)
+	if err != nil {
+		goreland.LogError("%v", err)
+		return
+	}
+
+	fmt.Fprintln(s.out, out)
+}
+
 func parseReplArgs(opts *argparse.Opts) {
 	flag := flag.NewFlagSet("fener repl", flag.ExitOnError)
 
@@ -30,45 +105,69 @@ func Entry(opts *argparse.Opts) {
 
 	parseReplArgs(opts)
 
-	rg, err := regolith.New(&regolith.Config{
-		StartWords: []string{"if", "fn", "while", "class"},
-		EndWords:   []string{"end"},
-	})
+	s := newSession(opts, os.Stdout)
+
+	rd, err := newReader(historyFile(), &completer{session: s})
 
 	if err != nil {
-		goreland.LogFatal("Error initializing regolith: %v", err)
+		goreland.LogFatal("Error initializing readline: %v", err)
 	}
 
-	defer rg.Close()
-
-	env := object.NewEnvironment()
+	defer rd.Close()
 
 	for true {
 
-		line, err := rg.Input()
+		line, err := rd.Input()
+
+		if err == io.EOF {
+			return
+		}
 
 		if err != nil {
 			goreland.LogFatal("Error reading input: %v", err)
 		}
 
-		ast, errors := parseLine(line)
-
-		if len(errors) > 0 {
-			for _, err := range errors {
-				goreland.LogError(err)
-			}
-			continue
+		if err := s.handle(line); err == errQuit {
+			return
 		}
+	}
+}
 
-		if opts.PrintAST {
-			printAST(ast)
+// handle runs a meta command or evaluates the input.
+func (s *session) handle(line string) error {
+	if strings.HasPrefix(line, ":") {
+		return s.command(line)
+	}
+
+	s.evaluate(line)
+
+	return nil
+}
+
+func (s *session) evaluate(line string) {
+	if s.printTokens {
+		printTokens(s.out, line)
+	}
+
+	ast, errors := parseLine(line)
+
+	if len(errors) > 0 {
+		for _, err := range errors {
+			goreland.LogError(err)
 		}
+		return
+	}
+
+	if s.opts.PrintAST {
+		printAST(ast)
+	}
 
-		e := eval.New(func(err error) {
-			goreland.LogError(err.Error())
-		})
+	start := time.Now()
 
-		fmt.Println(e.Eval(ast, env))
+	s.show(s.run(ast, line))
+
+	if s.timing {
+		fmt.Fprintf(s.out, "took %s\n", time.Since(start))
 	}
 }
 func parseLine(line string) (*ast.Program, []string) {
@@ -83,3 +182,11 @@ func parseLine(line string) (*ast.Program, []string) {
 func printAST(program *ast.Program) {
 	litter.Dump(program)
 }
+
+func printTokens(out io.Writer, line string) {
+	l := lexer.New(line)
+
+	for t := l.Next(); t.Type != token.EOF; t = l.Next() {
+		fmt.Fprintln(out, t)
+	}
+}
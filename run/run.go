@@ -3,6 +3,7 @@ package run
 import (
 	"flag"
 	"os"
+	"strings"
 
 	"github.com/pspiagicw/fener/argparse"
 	"github.com/pspiagicw/fener/ast"
@@ -19,9 +20,13 @@ func parseRunArgs(opts *argparse.Opts) {
 
 	flag := flag.NewFlagSet("fener run", flag.ExitOnError)
 
-	flag.Usage = help.Repl
+	flag.Usage = help.Run
 
 	flag.BoolVar(&opts.PrintAST, "print-ast", false, "Print the AST of the program")
+	flag.Func("allow", "Capabilities granted to the program", func(value string) error {
+		opts.Allow = append(opts.Allow, strings.Split(value, ",")...)
+		return nil
+	})
 
 	flag.Parse(opts.Args)
 
@@ -32,8 +37,14 @@ func Entry(opts *argparse.Opts) {
 
 	parseRunArgs(opts)
 
+	caps, err := object.ParseCapabilities(opts.Allow)
+
+	if err != nil {
+		goreland.LogFatal("Invalid --allow flag: %v", err)
+	}
+
 	for _, arg := range opts.Args {
-		env := object.NewEnvironment()
+		env := object.NewSandboxedEnvironment(caps)
 		ast, errors := parseFile(arg)
 
 		if len(errors) > 0 {
@@ -46,6 +57,7 @@ func Entry(opts *argparse.Opts) {
 		e := eval.New(func(err error) {
 			goreland.LogFatal(err.Error())
 		})
+		e.File = arg
 
 		e.Eval(ast, env)
 	}
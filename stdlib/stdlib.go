@@ -0,0 +1,8 @@
+package stdlib
+
+import "embed"
+
+// FS holds the standard library modules, written in fener itself.
+//
+//go:embed *.fn
+var FS embed.FS
@@ -34,6 +34,13 @@ const (
 	MOD      = "%"
 	BANG     = "!"
 
+	// Compound assignments apply the operator to the target and assign the result.
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	MULTIPLY_ASSIGN = "*="
+	DIVIDE_ASSIGN   = "/="
+	MOD_ASSIGN      = "%="
+
 	// Delimiters
 	LPAREN  = "("
 	RPAREN  = ")"
@@ -50,6 +57,7 @@ const (
 
 	FALSE = "FALSE"
 	TRUE  = "TRUE"
+	NULL  = "NULL"
 
 	FUNCTION = "FUNCTION"
 	RETURN   = "RETURN"
@@ -57,6 +65,15 @@ const (
 	THEN     = "THEN"
 	TEST     = "TEST"
 	CLASS    = "CLASS"
+	IMPORT   = "IMPORT"
+	AS       = "AS"
+	LET      = "LET"
+	CONST    = "CONST"
+	STATIC   = "STATIC"
+	TRAIT    = "TRAIT"
+	INCLUDE  = "INCLUDE"
+	MATCH    = "MATCH"
+	CASE     = "CASE"
 
 	AND = "AND"
 	OR  = "OR"
@@ -75,6 +92,17 @@ const (
 
 	// Misc
 
-	DOT   = "."
-	COMMA = ","
+	DOT      = "."
+	COMMA    = ","
+	COLON    = ":"
+	ELLIPSIS = "..."
+	// RANGE builds a range including its end, ELLIPSIS one leaving it out.
+	RANGE = ".."
+
+	// OPTIONAL_DOT accesses a field unless the target is null.
+	OPTIONAL_DOT = "?."
+	// COALESCE picks the right side when the left one is null.
+	COALESCE = "??"
+	// PIPE passes the left side as the first argument of the call on the right.
+	PIPE = "|>"
 )
@@ -1,14 +1,17 @@
 package vm
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 
+	"github.com/pspiagicw/fener/budget"
 	"github.com/pspiagicw/fener/code"
 	"github.com/pspiagicw/fener/object"
 )
 
 const StackSize = 2048
+const GlobalsSize = 65536
 
 type VM struct {
 	stack        []object.Object
@@ -17,6 +20,28 @@ type VM struct {
 	framePointer int
 
 	constants []object.Object
+	globals   []object.Object
+
+	// Budget limits the number of executed instructions, nil means unlimited.
+	// The VM makes no calls, so the depth limit of the budget is never checked.
+	Budget *budget.Budget
+
+	// Invoker calls the methods of instances overloading an operator.
+	Invoker object.Invoker
+}
+
+// operatorMethods are the methods instances define to overload an opcode.
+// GT swaps its operands and NEQ negates the result.
+var operatorMethods = map[code.OpCode]string{
+	code.ADD: "add",
+	code.SUB: "sub",
+	code.MUL: "mul",
+	code.DIV: "div",
+	code.MOD: "mod",
+	code.EQ:  "eq",
+	code.NEQ: "eq",
+	code.LT:  "lt",
+	code.GT:  "lt",
 }
 
 func New(bytes []byte, constants []object.Object) *VM {
@@ -26,6 +51,7 @@ func New(bytes []byte, constants []object.Object) *VM {
 		stackPointer: 0,
 
 		constants:    constants,
+		globals:      make([]object.Object, GlobalsSize),
 		frames:       []*Frame{mainFrame},
 		framePointer: 1,
 	}
@@ -53,6 +79,12 @@ func (vm *VM) Run() error {
 	var ip int
 	var ins []byte
 	for vm.currentFrame().IP < len(vm.currentFrame().Instructions) {
+		if vm.Budget != nil {
+			if err := vm.Budget.Step(); err != nil {
+				return err
+			}
+		}
+
 		ip = vm.currentFrame().IP
 		ins = vm.currentFrame().Instructions
 
@@ -66,17 +98,112 @@ func (vm *VM) Run() error {
 			vm.currentFrame().IP += 3
 
 			err = vm.push(constant)
-		case code.ADD:
+		case code.ADD, code.SUB, code.MUL, code.DIV, code.MOD:
 			right := vm.pop()
 			left := vm.pop()
 
-			result := vm.add(left, right)
+			vm.currentFrame().IP++
+
+			err = vm.arithmetic(code.OpCode(instr), left, right)
+		case code.GT, code.LT, code.EQ, code.NEQ, code.AND, code.OR:
+			right := vm.pop()
+			left := vm.pop()
+
+			vm.currentFrame().IP++
+
+			err = vm.comparison(code.OpCode(instr), left, right)
+		case code.TRUE:
+			vm.currentFrame().IP++
+
+			err = vm.push(&object.Boolean{Value: true})
+		case code.FALSE:
+			vm.currentFrame().IP++
+
+			err = vm.push(&object.Boolean{Value: false})
+		case code.NULL:
+			vm.currentFrame().IP++
+
+			err = vm.push(&object.Null{})
+		case code.NOT:
+			value := vm.pop()
+
+			vm.currentFrame().IP++
+
+			err = vm.push(&object.Boolean{Value: !isTruthy(value)})
+		case code.NEG:
+			value := vm.pop()
+
+			vm.currentFrame().IP++
 
-			if result != nil {
-				err = vm.push(result)
+			number, ok := value.(*object.Integer)
+			if !ok {
+				err = fmt.Errorf("unsupported operand for %s: %s", code.NEG, value.Type())
+				break
 			}
+			err = vm.push(&object.Integer{Value: -number.Value})
+		case code.JMP:
+			operand := binary.BigEndian.Uint16(ins[ip+1:])
+
+			vm.currentFrame().IP = int(operand)
+		case code.JCMP:
+			operand := binary.BigEndian.Uint16(ins[ip+1:])
+			condition := vm.pop()
+
+			if isTruthy(condition) {
+				vm.currentFrame().IP += 3
+			} else {
+				vm.currentFrame().IP = int(operand)
+			}
+		case code.JNOTNULL:
+			operand := binary.BigEndian.Uint16(ins[ip+1:])
+
+			if vm.StackTop().Type() != object.NULL_OBJ {
+				vm.currentFrame().IP = int(operand)
+			} else {
+				vm.pop()
+				vm.currentFrame().IP += 3
+			}
+		case code.NOMATCH:
+			err = fmt.Errorf("No case matches %s", vm.pop().String())
+		case code.RANGE:
+			operand := binary.BigEndian.Uint16(ins[ip+1:])
+			end := vm.pop()
+			start := vm.pop()
+
+			vm.currentFrame().IP += 3
+
+			err = vm.buildRange(start, end, operand == 1)
+		case code.INDEX:
+			index := vm.pop()
+			left := vm.pop()
+
+			vm.currentFrame().IP++
+
+			err = vm.index(left, index)
+		case code.SLICE:
+			end := vm.pop()
+			start := vm.pop()
+			left := vm.pop()
 
 			vm.currentFrame().IP++
+
+			var value object.Object
+			value, err = object.Slice(left, start, end)
+			if err == nil {
+				err = vm.push(value)
+			}
+		case code.SET:
+			operand := binary.BigEndian.Uint16(ins[ip+1:])
+
+			vm.currentFrame().IP += 3
+
+			vm.globals[operand] = vm.pop()
+		case code.GET:
+			operand := binary.BigEndian.Uint16(ins[ip+1:])
+
+			vm.currentFrame().IP += 3
+
+			err = vm.push(vm.globals[operand])
 		default:
 			err = fmt.Errorf("unknown opcode %s", code.OpCode(instr))
 		}
@@ -87,15 +214,166 @@ func (vm *VM) Run() error {
 
 	return nil
 }
-func (vm *VM) add(left, right object.Object) object.Object {
-	if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
-		leftVal := left.(*object.Integer).Value
-		rightVal := right.(*object.Integer).Value
+func (vm *VM) arithmetic(op code.OpCode, left, right object.Object) error {
+	if value, ok, err := object.CallMethod(vm.Invoker, left, operatorMethods[op], right); ok {
+		if err != nil {
+			return err
+		}
+		return vm.push(value)
+	}
+
+	leftInt, ok := left.(*object.Integer)
+	if !ok {
+		return fmt.Errorf("unsupported operand for %s: %s", op, left.Type())
+	}
+
+	rightInt, ok := right.(*object.Integer)
+	if !ok {
+		return fmt.Errorf("unsupported operand for %s: %s", op, right.Type())
+	}
+
+	var result int64
+
+	switch op {
+	case code.ADD:
+		result = leftInt.Value + rightInt.Value
+	case code.SUB:
+		result = leftInt.Value - rightInt.Value
+	case code.MUL:
+		result = leftInt.Value * rightInt.Value
+	case code.DIV:
+		if rightInt.Value == 0 {
+			return fmt.Errorf("division by zero")
+		}
+		result = leftInt.Value / rightInt.Value
+	case code.MOD:
+		if rightInt.Value == 0 {
+			return fmt.Errorf("division by zero")
+		}
+		result = leftInt.Value % rightInt.Value
+	}
+
+	return vm.push(&object.Integer{Value: result})
+}
+
+// index looks up a value like the evaluator does, instances are indexed by their index method.
+func (vm *VM) index(left, index object.Object) error {
+	if instance, ok := left.(*object.Instance); ok {
+		value, ok, err := object.CallMethod(vm.Invoker, instance, "index", index)
+		if !ok {
+			return fmt.Errorf("Can't index instance of %s, it has no index method", instance.Class.Name)
+		}
+		if err != nil {
+			return err
+		}
+		return vm.push(value)
+	}
+
+	value, err := object.Index(left, index)
+	if err != nil {
+		return err
+	}
+	return vm.push(value)
+}
 
-		return &object.Integer{Value: leftVal + rightVal}
+// Invoke lets builtins call back through the VM, builtins are called directly and
+// functions and classes are handed to the Invoker.
+// The VM has no call instruction of its own, without an Invoker only builtins can be called.
+func (vm *VM) Invoke(fn object.Object, args ...object.Object) (object.Object, error) {
+	if builtin, ok := fn.(*object.Builtin); ok {
+		return builtin.Call(vm, args...)
 	}
+	return object.Invoke(vm.Invoker, fn, args...)
+}
 
-	return nil
+// Step charges a unit of work done by a builtin to the VM's budget.
+func (vm *VM) Step() error {
+	if vm.Budget == nil {
+		return nil
+	}
+	return vm.Budget.Step()
+}
+
+// Context returns the context of the VM's budget, builtins waiting on I/O stop when it's done.
+func (vm *VM) Context() context.Context {
+	if vm.Budget == nil {
+		return context.Background()
+	}
+	return vm.Budget.Context()
+}
+func (vm *VM) buildRange(start, end object.Object, exclusive bool) error {
+	startInt, ok := start.(*object.Integer)
+	if !ok {
+		return fmt.Errorf("Range bounds must be integers, got %s and %s", start.Type(), end.Type())
+	}
+
+	endInt, ok := end.(*object.Integer)
+	if !ok {
+		return fmt.Errorf("Range bounds must be integers, got %s and %s", start.Type(), end.Type())
+	}
+
+	r, err := object.NewRange(startInt.Value, endInt.Value, exclusive)
+	if err != nil {
+		return err
+	}
+
+	return vm.push(r)
+}
+func (vm *VM) comparison(op code.OpCode, left, right object.Object) error {
+	receiver, arg := left, right
+	if op == code.GT {
+		receiver, arg = right, left
+	}
+
+	if value, ok, err := object.CallMethod(vm.Invoker, receiver, operatorMethods[op], arg); ok {
+		if err != nil {
+			return err
+		}
+		return vm.push(&object.Boolean{Value: isTruthy(value) != (op == code.NEQ)})
+	}
+
+	var result bool
+
+	switch op {
+	case code.EQ, code.NEQ:
+		equal, err := object.Equal(left, right, vm.Invoker)
+		if err != nil {
+			return err
+		}
+		result = equal != (op == code.NEQ)
+	case code.AND:
+		result = isTruthy(left) && isTruthy(right)
+	case code.OR:
+		result = isTruthy(left) || isTruthy(right)
+	case code.GT, code.LT:
+		leftInt, ok := left.(*object.Integer)
+		if !ok {
+			return fmt.Errorf("unsupported operand for %s: %s", op, left.Type())
+		}
+
+		rightInt, ok := right.(*object.Integer)
+		if !ok {
+			return fmt.Errorf("unsupported operand for %s: %s", op, right.Type())
+		}
+
+		if op == code.GT {
+			result = leftInt.Value > rightInt.Value
+		} else {
+			result = leftInt.Value < rightInt.Value
+		}
+	}
+
+	return vm.push(&object.Boolean{Value: result})
+}
+func isTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return obj.Value != 0
+	case *object.Boolean:
+		return obj.Value
+	default:
+		return false
+	}
 }
 
 func (vm *VM) StackTop() object.Object {
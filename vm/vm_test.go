@@ -1,10 +1,14 @@
 package vm
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/pspiagicw/fener/budget"
 	"github.com/pspiagicw/fener/code"
 	"github.com/pspiagicw/fener/compile"
+	"github.com/pspiagicw/fener/eval"
 	"github.com/pspiagicw/fener/lexer"
 	"github.com/pspiagicw/fener/object"
 	"github.com/pspiagicw/fener/parser"
@@ -20,11 +24,163 @@ func TestVM(t *testing.T) {
 	tt := []vmTest{
 		{"1", 1},
 		{"1 + 2", 3},
+		{"(1 + 2) * 3 - 8 / 2", 5},
+		{"1 < 2", true},
+		{"!(1 > 2) && 2 == 2", true},
+		{"a = 5 b = a * 2 b", 10},
+		{"if 1 > 2 then 10 else 20 end", 20},
+		{"i = 0 while i < 10 then i = i + 1 end i", 10},
+		{"i = 0 while i < 10 then i += 3 end i", 12},
+		{"a = 20 a -= 2 a /= 3 a *= 4 a %= 7 a", 3},
+		{"(1..10)[3]", 3},
+		{"r = 1...5 r[4]", 4},
+		{"(1..10)[2:5][4]", 5},
+		{"-(2 - 5)", 3},
+		{"(1..10)[:3] == 1..3", true},
+		{"(1..10)[-2:] == 9...11", true},
+		{"null", nil},
+		{"null == null", true},
+		{"1 == null", false},
+		{"true != 1", true},
+		{"null ?? 5", 5},
+		{"a = 3 a ?? 5", 3},
+		{"null ?? null ?? 2 + 1", 3},
+		{"match 2 case 1 then 10 case 2 then 20 case _ then 30 end", 20},
+		{"match 7 case 1 then 10 case _ then 30 end", 30},
+		{"match 7 case n if n > 5 then n * 2 case n then n end", 14},
+		{"match 3 case n if n > 5 then n * 2 case n then n end", 3},
+		{"match true case false then 1 case true then 2 end", 2},
+		{"n = 1 match 7 case n then n end n", 1},
+		{"n = 1 m = match 7 case n then n end m + n", 8},
+		{"x = 1 match 2 case y then x = y end x", 2},
+		{"match 1 case a then a end match 2 case b then b end", 2},
 	}
 
 	testVM(t, tt)
 }
 
+func TestBudget(t *testing.T) {
+	bytes, constants := compileInput(t, "while true then end")
+
+	vm := New(bytes, constants)
+	vm.Budget = budget.New(context.Background(), budget.Limits{MaxSteps: 1000})
+
+	err := vm.Run()
+
+	var limitErr *budget.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != budget.STEPS {
+		t.Fatalf("Expected step limit error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	vm = New(bytes, constants)
+	vm.Budget = budget.New(ctx, budget.Limits{})
+
+	err = vm.Run()
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancellation error, got %v", err)
+	}
+
+	// Builtins called through the VM walk sequences within its budget.
+	env := object.NewEnvironment()
+	vm = New(nil, nil)
+	vm.Budget = budget.New(context.Background(), budget.Limits{MaxSteps: 1000})
+
+	_, err = vm.Invoke(env.Get("map"), &object.Range{Start: 1, End: 4000000000}, env.Get("str"))
+
+	if !errors.As(err, &limitErr) || limitErr.Limit != budget.STEPS {
+		t.Fatalf("Expected step limit error, got %v", err)
+	}
+}
+
+func TestOperatorMethods(t *testing.T) {
+	program := parser.New(lexer.New(`
+    class Money
+        fn init(cents) this.cents = cents end
+        fn add(other) Money(this.cents + other.cents) end
+        fn eq(other) this.cents == other.cents end
+        fn lt(other) this.cents < other.cents end
+        fn index(i) this.cents * i end
+    end
+    a = Money(100)
+    b = Money(250)
+    `)).Parse()
+
+	e := eval.New(func(err error) {
+		t.Fatalf("eval error: %v", err)
+	})
+	env := object.NewEnvironment()
+	e.Eval(program, env)
+
+	a, b := env.Get("a"), env.Get("b")
+
+	tt := []struct {
+		op       code.OpCode
+		left     object.Object
+		right    object.Object
+		expected interface{}
+	}{
+		{code.EQ, a, a, true},
+		{code.NEQ, a, b, true},
+		{code.LT, a, b, true},
+		{code.GT, a, b, false},
+		{code.GT, b, a, true},
+		{code.INDEX, a, &object.Integer{Value: 3}, 300},
+	}
+
+	for _, tc := range tt {
+		bytes := code.ToBytes([]*code.Instruction{code.Make(code.PUSH, 0), code.Make(code.PUSH, 1), code.Make(tc.op)})
+
+		vm := New(bytes, []object.Object{tc.left, tc.right})
+		vm.Invoker = e
+
+		if err := vm.Run(); err != nil {
+			t.Fatalf("error running vm: %v", err)
+		}
+
+		testValue(t, vm.StackTop(), tc.expected)
+	}
+
+	bytes := code.ToBytes([]*code.Instruction{code.Make(code.PUSH, 0), code.Make(code.PUSH, 1), code.Make(code.ADD)})
+
+	vm := New(bytes, []object.Object{a, b})
+	vm.Invoker = e
+
+	if err := vm.Run(); err != nil {
+		t.Fatalf("error running vm: %v", err)
+	}
+
+	sum, ok := vm.StackTop().(*object.Instance)
+	if !ok {
+		t.Fatalf("Expected an instance, got %T", vm.StackTop())
+	}
+
+	testValue(t, sum.Map["cents"], 350)
+}
+
+func TestNoMatch(t *testing.T) {
+	bytes, constants := compileInput(t, "match 5 case 1 then 1 end")
+
+	err := New(bytes, constants).Run()
+
+	if err == nil || err.Error() != "No case matches int(5)" {
+		t.Fatalf("Expected no match error, got %v", err)
+	}
+}
+
+func TestRangeTooLong(t *testing.T) {
+	bytes, constants := compileInput(t, "0..9223372036854775807")
+
+	err := New(bytes, constants).Run()
+
+	if err == nil || err.Error() != "Range 0..9223372036854775807 has too many elements" {
+		t.Fatalf("Expected range length error, got %v", err)
+	}
+}
+
 func testVM(t *testing.T, tt []vmTest) {
 	for _, tc := range tt {
 		t.Run(tc.input, func(t *testing.T) {
@@ -77,6 +233,23 @@ func testValue(t *testing.T, val object.Object, expected interface{}) {
 	switch expected := expected.(type) {
 	case int:
 		testIntegerObject(t, val, int64(expected))
+	case bool:
+		testBooleanObject(t, val, expected)
+	case nil:
+		if val.Type() != object.NULL_OBJ {
+			t.Errorf("object is not Null, while expected one. got=%T (%+v)", val, val)
+		}
+	}
+}
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) {
+	result, ok := obj.(*object.Boolean)
+
+	if !ok {
+		t.Fatalf("object is not Boolean, while expected one. got=%T (%+v)", obj, obj)
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, want=%t", result.Value, expected)
 	}
 }
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
@@ -90,3 +263,37 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
 		t.Errorf("object has wrong value. got=%d, want=%d", result.Value, expected)
 	}
 }
+
+func TestInvoke(t *testing.T) {
+	program := parser.New(lexer.New(`double = fn(x) x * 2 end`)).Parse()
+
+	e := eval.New(func(err error) {
+		t.Fatalf("eval error: %v", err)
+	})
+	env := object.NewEnvironment()
+	e.Eval(program, env)
+
+	vm := New(nil, nil)
+
+	mapper := env.Get("map").(*object.Builtin)
+	xs := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}}}
+
+	// Builtins are called by the VM itself.
+	result, err := mapper.Call(vm, xs, env.Get("str"))
+	if err != nil || result.Pretty() != "[1, 2]" {
+		t.Fatalf("Expected [1, 2], got %v (%v)", result, err)
+	}
+
+	// Functions need an evaluator behind the VM.
+	_, err = mapper.Call(vm, xs, env.Get("double"))
+	if err == nil || err.Error() != "can't call FUNCTION without an invoker" {
+		t.Fatalf("Expected missing invoker error, got %v", err)
+	}
+
+	vm.Invoker = e
+
+	result, err = mapper.Call(vm, xs, env.Get("double"))
+	if err != nil || result.Pretty() != "[2, 4]" {
+		t.Fatalf("Expected [2, 4], got %v (%v)", result, err)
+	}
+}
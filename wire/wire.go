@@ -0,0 +1,66 @@
+// Package wire reads and writes the Content-Length framed messages used by
+// the language server and debug adapter protocols.
+package wire
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Read returns the body of the next message.
+func Read(r *bufio.Reader) ([]byte, error) {
+	length := -1
+
+	for {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q", line)
+		}
+
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// Write sends body as a single message.
+func Write(w io.Writer, body []byte) error {
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+
+	_, err := w.Write(body)
+
+	return err
+}
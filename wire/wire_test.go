@@ -0,0 +1,37 @@
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestReadWrite(t *testing.T) {
+	var buf bytes.Buffer
+
+	messages := []string{`{"id":1}`, `{"method":"exit"}`}
+
+	for _, message := range messages {
+		if err := Write(&buf, []byte(message)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	r := bufio.NewReader(&buf)
+
+	for _, expected := range messages {
+		body, err := Read(r)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if string(body) != expected {
+			t.Errorf("Expected %q, got %q", expected, string(body))
+		}
+	}
+
+	if _, err := Read(bufio.NewReader(bytes.NewBufferString("Content-Type: json\r\n\r\n{}"))); err == nil {
+		t.Errorf("Expected error for missing Content-Length")
+	}
+}